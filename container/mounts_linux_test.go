@@ -0,0 +1,54 @@
+package container
+
+import "testing"
+
+func TestSupportsIDMappedMountsIsStableAndCached(t *testing.T) {
+	first := SupportsIDMappedMounts()
+	second := SupportsIDMappedMounts()
+	if first != second {
+		t.Fatalf("SupportsIDMappedMounts returned different results across calls: %v then %v", first, second)
+	}
+}
+
+func TestIDMappedMountOptionsNoMappingsPassesRecursiveBindThrough(t *testing.T) {
+	for _, recursive := range []bool{false, true} {
+		m := &Mount{RecursiveBind: recursive}
+		uids, gids, gotRecursive, err := m.IDMappedMountOptions()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if uids != nil || gids != nil {
+			t.Fatalf("expected no id mappings, got uids=%v gids=%v", uids, gids)
+		}
+		if gotRecursive != recursive {
+			t.Fatalf("recursive = %v, want %v", gotRecursive, recursive)
+		}
+	}
+}
+
+func TestIDMappedMountOptionsWithMappings(t *testing.T) {
+	m := &Mount{
+		UIDMappings: []IDMap{{Container: 0, Host: 100000, Size: 65536}},
+		GIDMappings: []IDMap{{Container: 0, Host: 200000, Size: 65536}},
+	}
+
+	uids, gids, recursive, err := m.IDMappedMountOptions()
+	if !SupportsIDMappedMounts() {
+		if err != ErrIDMappedMountsNotSupported {
+			t.Fatalf("err = %v, want ErrIDMappedMountsNotSupported", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !recursive {
+		t.Fatal("id-mapped mounts must always be recursive")
+	}
+	if len(uids) != 1 || uids[0].ContainerID != 0 || uids[0].HostID != 100000 || uids[0].Size != 65536 {
+		t.Fatalf("unexpected uid mappings: %+v", uids)
+	}
+	if len(gids) != 1 || gids[0].ContainerID != 0 || gids[0].HostID != 200000 || gids[0].Size != 65536 {
+		t.Fatalf("unexpected gid mappings: %+v", gids)
+	}
+}
@@ -2,6 +2,15 @@
 
 package container
 
+// IDMap describes a single line of a uid or gid mapping, in the same terms
+// used by Linux's /proc/<pid>/{u,g}id_map and the OCI runtime spec's
+// Linux.UIDMappings/GIDMappings.
+type IDMap struct {
+	Container int `json:"container"`
+	Host      int `json:"host"`
+	Size      int `json:"size"`
+}
+
 // Mount contains information for a mount operation.
 type Mount struct {
 	Source                 string `json:"source"`
@@ -12,4 +21,23 @@ type Mount struct {
 	NonRecursive           bool   `json:"nonrecursive"`
 	ReadOnlyNonRecursive   bool   `json:"readonlynonrecursive"`
 	ReadOnlyForceRecursive bool   `json:"readonlyforcerecursive"`
+
+	// RecursiveBind requests that a bind mount also recursively bind-mount
+	// any mounts found underneath Source, without making it read-only. It's
+	// mutually exclusive with NonRecursive and the ReadOnly* variants above.
+	RecursiveBind bool `json:"recursivebind"`
+
+	// UIDMappings and GIDMappings, when non-empty, request that this mount
+	// be attached with a runtime-idmapped mount (Linux 5.12+
+	// mount_setattr(MOUNT_ATTR_IDMAP)) using the given id mappings, instead
+	// of relying on the container's own user namespace.
+	// IDMappedMountOptions reports ErrIDMappedMountsNotSupported if the host
+	// kernel can't do this.
+	//
+	// IDMappedMountOptions (mounts_linux.go) turns these two fields, plus
+	// RecursiveBind above, into the OCI runtime-spec Mount pieces an OCI
+	// spec generator needs; no such generator exists in this tree yet, so
+	// nothing calls it.
+	UIDMappings []IDMap `json:"uidmappings,omitempty"`
+	GIDMappings []IDMap `json:"gidmappings,omitempty"`
 }
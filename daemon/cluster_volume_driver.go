@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/moby/moby/api/types/volume"
+)
+
+// errNotClusterVolume is returned by CreateClusterVolume when
+// options.ClusterVolumeSpec is nil, so its caller can tell "this isn't a
+// cluster volume, fall through to the regular single-node volume store"
+// apart from a real dispatch failure.
+var errNotClusterVolume = errors.New("not a cluster volume")
+
+// ClusterVolumeDriver is implemented by CSI-style plugins that back a
+// cluster-scoped volume (one created with a non-nil
+// volume.CreateOptions.ClusterVolumeSpec). Unlike the plain volume.Driver
+// interface, a cluster volume has topology and lifecycle that span the
+// whole swarm, not just the node it happens to be used on.
+type ClusterVolumeDriver interface {
+	Create(ctx context.Context, name string, spec *volume.ClusterVolumeSpec) error
+	Remove(ctx context.Context, name string) error
+	Publish(ctx context.Context, name, nodeID string) error
+	Unpublish(ctx context.Context, name, nodeID string) error
+	List(ctx context.Context) ([]string, error)
+}
+
+var (
+	clusterVolumeDriversMu sync.Mutex
+	clusterVolumeDrivers   = map[string]ClusterVolumeDriver{}
+)
+
+// RegisterClusterVolumeDriver makes a CSI-style plugin available to satisfy
+// volume.CreateOptions that carry a ClusterVolumeSpec and whose Driver
+// matches name.
+func RegisterClusterVolumeDriver(name string, driver ClusterVolumeDriver) error {
+	clusterVolumeDriversMu.Lock()
+	defer clusterVolumeDriversMu.Unlock()
+	if _, ok := clusterVolumeDrivers[name]; ok {
+		return fmt.Errorf("cluster volume driver already registered: %s", name)
+	}
+	clusterVolumeDrivers[name] = driver
+	return nil
+}
+
+// getClusterVolumeDriver looks up the registered ClusterVolumeDriver for a
+// volume create request, dispatching on options.Driver (the same field a
+// plain, non-cluster volume.CreateOptions names its driver with;
+// ClusterVolumeSpec carries no driver name of its own).
+func getClusterVolumeDriver(options volume.CreateOptions) (ClusterVolumeDriver, error) {
+	name := options.Driver
+	clusterVolumeDriversMu.Lock()
+	defer clusterVolumeDriversMu.Unlock()
+	driver, ok := clusterVolumeDrivers[name]
+	if !ok {
+		return nil, fmt.Errorf("no cluster volume driver named %q is registered", name)
+	}
+	return driver, nil
+}
+
+// CreateClusterVolume dispatches a volume create request that carries a
+// ClusterVolumeSpec to the registered ClusterVolumeDriver named by
+// options.Driver, instead of the regular single-node volume store. Callers
+// should try this first and fall back to the regular store on
+// errNotClusterVolume; any other error means a cluster volume was requested
+// but couldn't be created.
+func CreateClusterVolume(ctx context.Context, options volume.CreateOptions) error {
+	if options.ClusterVolumeSpec == nil {
+		return errNotClusterVolume
+	}
+	driver, err := getClusterVolumeDriver(options)
+	if err != nil {
+		return err
+	}
+	return driver.Create(ctx, options.Name, options.ClusterVolumeSpec)
+}
@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/volume"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type fakeClusterVolumeDriver struct {
+	createName string
+	createSpec *volume.ClusterVolumeSpec
+}
+
+func (f *fakeClusterVolumeDriver) Create(_ context.Context, name string, spec *volume.ClusterVolumeSpec) error {
+	f.createName = name
+	f.createSpec = spec
+	return nil
+}
+func (fakeClusterVolumeDriver) Remove(context.Context, string) error          { return nil }
+func (fakeClusterVolumeDriver) Publish(context.Context, string, string) error { return nil }
+func (fakeClusterVolumeDriver) Unpublish(context.Context, string, string) error {
+	return nil
+}
+func (fakeClusterVolumeDriver) List(context.Context) ([]string, error) { return nil, nil }
+
+func TestClusterVolumeDriverRegistry(t *testing.T) {
+	clusterVolumeDriversMu.Lock()
+	clusterVolumeDrivers = map[string]ClusterVolumeDriver{}
+	clusterVolumeDriversMu.Unlock()
+
+	_, err := getClusterVolumeDriver(volume.CreateOptions{Driver: "csi-unknown"})
+	assert.ErrorContains(t, err, `no cluster volume driver named "csi-unknown" is registered`)
+
+	fake := &fakeClusterVolumeDriver{}
+	assert.NilError(t, RegisterClusterVolumeDriver("csi-test", fake))
+
+	driver, err := getClusterVolumeDriver(volume.CreateOptions{Driver: "csi-test"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(driver, ClusterVolumeDriver(fake)))
+
+	err = RegisterClusterVolumeDriver("csi-test", fake)
+	assert.ErrorContains(t, err, "cluster volume driver already registered: csi-test")
+}
+
+func TestCreateClusterVolumeNotClusterSpec(t *testing.T) {
+	err := CreateClusterVolume(context.Background(), volume.CreateOptions{Name: "vol"})
+	assert.Equal(t, err, errNotClusterVolume)
+}
+
+func TestCreateClusterVolumeUnregisteredDriver(t *testing.T) {
+	clusterVolumeDriversMu.Lock()
+	clusterVolumeDrivers = map[string]ClusterVolumeDriver{}
+	clusterVolumeDriversMu.Unlock()
+
+	err := CreateClusterVolume(context.Background(), volume.CreateOptions{
+		Name:              "vol",
+		Driver:            "csi-unknown",
+		ClusterVolumeSpec: &volume.ClusterVolumeSpec{},
+	})
+	assert.ErrorContains(t, err, `no cluster volume driver named "csi-unknown" is registered`)
+}
+
+func TestCreateClusterVolumeDispatchesToDriver(t *testing.T) {
+	clusterVolumeDriversMu.Lock()
+	clusterVolumeDrivers = map[string]ClusterVolumeDriver{}
+	clusterVolumeDriversMu.Unlock()
+
+	fake := &fakeClusterVolumeDriver{}
+	assert.NilError(t, RegisterClusterVolumeDriver("csi-test", fake))
+
+	spec := &volume.ClusterVolumeSpec{Group: "group-a"}
+	err := CreateClusterVolume(context.Background(), volume.CreateOptions{
+		Name:              "vol",
+		Driver:            "csi-test",
+		ClusterVolumeSpec: spec,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, fake.createName, "vol")
+	assert.Check(t, is.Equal(fake.createSpec, spec))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -36,21 +37,50 @@ const (
 
 	// userlandProxyBinary is the name of the userland-proxy binary.
 	userlandProxyBinary = "docker-proxy"
+
+	// defaultBridgeNATBackend is the default datapath used for bridge
+	// network NAT; see BridgeConfig.NATBackend.
+	defaultBridgeNATBackend = "iptables"
 )
 
 // BridgeConfig stores all the parameters for both the bridge driver and the default bridge network.
 type BridgeConfig struct {
 	DefaultBridgeConfig
 
-	EnableIPTables           bool   `json:"iptables,omitempty"`
-	EnableIP6Tables          bool   `json:"ip6tables,omitempty"`
-	EnableIPForward          bool   `json:"ip-forward,omitempty"`
-	DisableFilterForwardDrop bool   `json:"ip-forward-no-drop,omitempty"`
-	EnableIPMasq             bool   `json:"ip-masq,omitempty"`
-	EnableUserlandProxy      bool   `json:"userland-proxy,omitempty"`
-	UserlandProxyPath        string `json:"userland-proxy-path,omitempty"`
-	AllowDirectRouting       bool   `json:"allow-direct-routing,omitempty"`
-	BridgeAcceptFwMark       string `json:"bridge-accept-fwmark,omitempty"`
+	EnableIPTables           bool `json:"iptables,omitempty"`
+	EnableIP6Tables          bool `json:"ip6tables,omitempty"`
+	EnableIPForward          bool `json:"ip-forward,omitempty"`
+	DisableFilterForwardDrop bool `json:"ip-forward-no-drop,omitempty"`
+
+	// EnableIPMasq is deprecated in favour of EnableIP4Masquerade and
+	// EnableIP6Masquerade, which are meant to allow masquerading to be
+	// toggled independently per address family. It's kept around so that
+	// config files written before the split keep working: setPlatformDefaults
+	// copies it into both new fields when they're left unset.
+	//
+	// EnableIP4Masquerade/EnableIP6Masquerade are daemon-config-level
+	// placeholders only: the per-family split they're meant to drive belongs
+	// on the bridge driver's own networkConfiguration (EnableIPMasquerade
+	// there is still a single flag), threaded through setupIP4Tables/
+	// setupIP6Tables and exposed via network-create driver options and
+	// `docker network inspect`. None of that wiring exists yet, so setting
+	// either of these daemon flags today has no effect on any bridge
+	// network's NAT rules.
+	EnableIPMasq        bool   `json:"ip-masq,omitempty"`
+	EnableIP4Masquerade bool   `json:"ip4-masq,omitempty"`
+	EnableIP6Masquerade bool   `json:"ip6-masq,omitempty"`
+	EnableUserlandProxy bool   `json:"userland-proxy,omitempty"`
+	UserlandProxyPath   string `json:"userland-proxy-path,omitempty"`
+	AllowDirectRouting  bool   `json:"allow-direct-routing,omitempty"`
+	BridgeAcceptFwMark  string `json:"bridge-accept-fwmark,omitempty"`
+
+	// NATBackend selects the datapath used for outgoing SNAT/MASQUERADE and
+	// published-port DNAT on bridge networks. "iptables" (the default) is
+	// the only backend that exists today; there is no IPVS datapath in this
+	// daemon -- no netlink IPVS client, no port-mapper dispatch, no loopback
+	// fallback -- so "ipvs" isn't a recognized value here, the same as any
+	// other unimplemented or misspelled backend name.
+	NATBackend string `json:"bridge-nat-backend,omitempty"`
 }
 
 // DefaultBridgeConfig stores all the parameters for the default bridge network.
@@ -94,6 +124,19 @@ type Config struct {
 	// ResolvConf is the path to the configuration of the host resolver
 	ResolvConf string `json:"resolv-conf,omitempty"`
 	Rootless   bool   `json:"rootless,omitempty"`
+
+	// DefaultRuntime is the runtime used for containers that don't specify
+	// one explicitly. It must be either StockRuntimeName or a key in
+	// Runtimes; this lets operators standardize on an alternative OCI
+	// runtime (crun, youki, runsc, kata, ...) without overriding it on
+	// every container.
+	DefaultRuntime string `json:"default-runtime,omitempty"`
+
+	// OOMScoreAdjust biases the OOM killer against (a negative value) or
+	// towards (a positive value) the dockerd process itself, by writing to
+	// /proc/self/oom_score_adj on startup. It doesn't affect containers,
+	// which get their own oom-score-adj via HostConfig.
+	OOMScoreAdjust int `json:"oom-score-adjust,omitempty"`
 }
 
 // GetExecRoot returns the user configured Exec-root
@@ -152,8 +195,11 @@ func setPlatformDefaults(cfg *Config) error {
 	cfg.Ulimits = make(map[string]*container.Ulimit)
 	cfg.ShmSize = opts.MemBytes(DefaultShmSize)
 	cfg.SeccompProfile = SeccompProfileDefault
+	cfg.BridgeConfig.NATBackend = defaultBridgeNATBackend
 	cfg.IpcMode = string(DefaultIpcMode)
 	cfg.Runtimes = make(map[string]system.Runtime)
+	cfg.DefaultRuntime = StockRuntimeName
+	cfg.OOMScoreAdjust = 0
 
 	if cgroups.Mode() != cgroups.Unified {
 		cfg.CgroupNamespaceMode = string(DefaultCgroupV1NamespaceMode)
@@ -161,6 +207,15 @@ func setPlatformDefaults(cfg *Config) error {
 		cfg.CgroupNamespaceMode = string(DefaultCgroupNamespaceMode)
 	}
 
+	// EnableIPMasq is the pre-split field; fold it into the per-family
+	// toggles so that, once something actually reads
+	// EnableIP4Masquerade/EnableIP6Masquerade (see the doc comment on
+	// BridgeConfig), it doesn't also have to special-case the legacy field.
+	if cfg.BridgeConfig.EnableIPMasq {
+		cfg.BridgeConfig.EnableIP4Masquerade = true
+		cfg.BridgeConfig.EnableIP6Masquerade = true
+	}
+
 	var err error
 	cfg.BridgeConfig.UserlandProxyPath, err = lookupBinPath(userlandProxyBinary)
 	if err != nil {
@@ -201,6 +256,26 @@ func setPlatformDefaults(cfg *Config) error {
 	return nil
 }
 
+// ApplyOOMScoreAdjust writes conf.OOMScoreAdjust to /proc/self/oom_score_adj.
+// Daemon.Reload (daemon/reload.go) calls it when the configuration is
+// reloaded; nothing in this tree calls it on daemon startup. A zero
+// OOMScoreAdjust leaves the current value alone. A permission error (as seen
+// running inside another container, or under RootlessKit without the right
+// caps) is logged rather than returned, so a restricted environment doesn't
+// fail daemon startup over a setting that's a best-effort nicety there
+// anyway.
+func (conf *Config) ApplyOOMScoreAdjust() error {
+	if conf.OOMScoreAdjust == 0 {
+		return nil
+	}
+	err := os.WriteFile("/proc/self/oom_score_adj", []byte(strconv.Itoa(conf.OOMScoreAdjust)), 0o644)
+	if err != nil && (conf.Rootless || os.IsPermission(err)) {
+		log.G(context.TODO()).WithError(err).Warn("failed to set oom-score-adjust for the daemon process")
+		return nil
+	}
+	return err
+}
+
 // lookupBinPath returns an absolute path to the provided binary by searching relevant "libexec" locations (per FHS 3.0 & 2.3) followed by PATH
 func lookupBinPath(binary string) (string, error) {
 	if filepath.IsAbs(binary) {
@@ -254,6 +329,15 @@ func validatePlatformConfig(conf *Config) error {
 	if err := validateFwMarkMask(conf.BridgeAcceptFwMark); err != nil {
 		return errors.Wrap(err, "invalid bridge-accept-fwmark")
 	}
+	if err := validateBridgeNATBackend(conf.BridgeConfig.NATBackend); err != nil {
+		return errors.Wrap(err, "invalid bridge-nat-backend")
+	}
+	if err := validateOOMScoreAdjust(conf.OOMScoreAdjust); err != nil {
+		return errors.Wrap(err, "invalid oom-score-adjust")
+	}
+	if err := validateDefaultRuntime(conf); err != nil {
+		return errors.Wrap(err, "invalid default-runtime")
+	}
 	return verifyDefaultCgroupNsMode(conf.CgroupNamespaceMode)
 }
 
@@ -313,6 +397,90 @@ func validateFirewallBackend(val string) error {
 	return errors.New(`allowed values are "iptables" and "nftables"`)
 }
 
+// natBackend is the datapath BridgeConfig.NATBackend selects between:
+// outgoing SNAT/MASQUERADE and published-port DNAT for bridge networks.
+// newNATBackend is the dispatch point a real port-mapper would construct
+// one through; today it has exactly one implementation (iptablesNATBackend).
+// There is no IPVS backend in this daemon: no netlink IPVS client, no
+// port-mapper dispatch, no loopback iptables fallback for traffic IPVS
+// can't intercept, and no natBackend implementation for it, so "ipvs" isn't
+// given special recognition here -- it's rejected the same way any other
+// unimplemented or misspelled value is.
+type natBackend interface {
+	// Name reports the datapath name, matching the NATBackend config
+	// value that selects it.
+	Name() string
+}
+
+// iptablesNATBackend is the only natBackend this daemon can actually
+// program traffic through today; the rule construction itself lives
+// outside this package, in the bridge driver.
+type iptablesNATBackend struct{}
+
+func (iptablesNATBackend) Name() string { return "iptables" }
+
+// newNATBackend constructs the natBackend named by val, the same value
+// BridgeConfig.NATBackend holds. Only "" and "iptables" construct one;
+// anything else, including "ipvs", is rejected as an unknown backend -- this
+// daemon has no implementation to dispatch an IPVS (or any other) datapath
+// to, so it isn't presented as a selectable option.
+func newNATBackend(val string) (natBackend, error) {
+	switch val {
+	case "", defaultBridgeNATBackend:
+		return iptablesNATBackend{}, nil
+	}
+	return nil, fmt.Errorf("unknown bridge-nat-backend %q", val)
+}
+
+// validateBridgeNATBackend checks that the configured bridge NAT datapath is
+// one newNATBackend can construct.
+func validateBridgeNATBackend(val string) error {
+	_, err := newNATBackend(val)
+	return err
+}
+
+// validateOOMScoreAdjust checks that val is within the range the kernel
+// accepts for /proc/<pid>/oom_score_adj.
+func validateOOMScoreAdjust(val int) error {
+	if val < -1000 || val > 1000 {
+		return fmt.Errorf("value %d is out of range [-1000, 1000]", val)
+	}
+	return nil
+}
+
+// validateDefaultRuntime checks that conf.DefaultRuntime, if set, names
+// either the built-in StockRuntimeName or a runtime registered in
+// conf.Runtimes, so a typo'd --default-runtime fails at daemon start rather
+// than on the first attempt to run a container.
+func validateDefaultRuntime(conf *Config) error {
+	if conf.DefaultRuntime == "" || conf.DefaultRuntime == StockRuntimeName {
+		return nil
+	}
+	if _, ok := conf.Runtimes[conf.DefaultRuntime]; !ok {
+		return fmt.Errorf("runtime %q is not registered", conf.DefaultRuntime)
+	}
+	return nil
+}
+
+// RuntimeName resolves the OCI runtime to use for a container, given the
+// runtime its HostConfig asked for (requested, empty if it didn't specify
+// one). requested always wins; conf.DefaultRuntime only applies when it's
+// empty. This is the selection DefaultRuntime exists to drive -- an operator
+// standardizing on an alternative OCI runtime shouldn't need every
+// container to pass --runtime explicitly -- but there's no container-create
+// code in this snapshot to call it from yet; the real call site is wherever
+// a HostConfig.Runtime is resolved to an OCI runtime before creating the
+// containerd task.
+func (conf *Config) RuntimeName(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if conf.DefaultRuntime != "" {
+		return conf.DefaultRuntime
+	}
+	return StockRuntimeName
+}
+
 func validateFwMarkMask(val string) error {
 	if val == "" {
 		return nil
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/system"
+	"gotest.tools/v3/assert"
+)
+
+// TestSetPlatformDefaultsIPMasqFold covers representative combinations of the
+// deprecated EnableIPMasq field against the per-family
+// EnableIP4Masquerade/EnableIP6Masquerade toggles it's folded into.
+func TestSetPlatformDefaultsIPMasqFold(t *testing.T) {
+	testcases := []struct {
+		name        string
+		ipMasq      bool
+		ip4MasqIn   bool
+		ip6MasqIn   bool
+		wantIP4Masq bool
+		wantIP6Masq bool
+	}{
+		{
+			name: "all unset",
+		},
+		{
+			name:        "legacy ip-masq folds into both families",
+			ipMasq:      true,
+			wantIP4Masq: true,
+			wantIP6Masq: true,
+		},
+		{
+			name:        "per-family fields set without legacy field",
+			ip4MasqIn:   true,
+			wantIP4Masq: true,
+		},
+		{
+			name:        "legacy field set alongside per-family fields",
+			ipMasq:      true,
+			ip4MasqIn:   true,
+			ip6MasqIn:   true,
+			wantIP4Masq: true,
+			wantIP6Masq: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.BridgeConfig.EnableIPMasq = tc.ipMasq
+			cfg.BridgeConfig.EnableIP4Masquerade = tc.ip4MasqIn
+			cfg.BridgeConfig.EnableIP6Masquerade = tc.ip6MasqIn
+
+			assert.NilError(t, setPlatformDefaults(cfg))
+			assert.Equal(t, cfg.BridgeConfig.EnableIP4Masquerade, tc.wantIP4Masq)
+			assert.Equal(t, cfg.BridgeConfig.EnableIP6Masquerade, tc.wantIP6Masq)
+		})
+	}
+}
+
+func TestValidateOOMScoreAdjust(t *testing.T) {
+	for _, val := range []int{-1000, 0, 1000} {
+		assert.NilError(t, validateOOMScoreAdjust(val))
+	}
+	for _, val := range []int{-1001, 1001} {
+		assert.ErrorContains(t, validateOOMScoreAdjust(val), "out of range")
+	}
+}
+
+// TestApplyOOMScoreAdjust checks that OOMScoreAdjust is actually written to
+// /proc/self/oom_score_adj, and that the zero value (meaning "leave it alone")
+// is a no-op rather than writing "0" over whatever the kernel already set.
+func TestApplyOOMScoreAdjust(t *testing.T) {
+	const path = "/proc/self/oom_score_adj"
+	orig, err := os.ReadFile(path)
+	if err != nil {
+		t.Skipf("can't read %s in this environment: %v", path, err)
+	}
+	defer os.WriteFile(path, orig, 0o644)
+
+	conf := &Config{OOMScoreAdjust: 0}
+	assert.NilError(t, conf.ApplyOOMScoreAdjust())
+	got, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(got)), strings.TrimSpace(string(orig)))
+
+	conf = &Config{OOMScoreAdjust: 100}
+	if err := conf.ApplyOOMScoreAdjust(); err != nil {
+		t.Skipf("can't write %s in this environment: %v", path, err)
+	}
+	got, err = os.ReadFile(path)
+	assert.NilError(t, err)
+	gotVal, err := strconv.Atoi(strings.TrimSpace(string(got)))
+	assert.NilError(t, err)
+	assert.Equal(t, gotVal, 100)
+}
+
+func TestValidateBridgeNATBackend(t *testing.T) {
+	for _, val := range []string{"", "iptables"} {
+		assert.NilError(t, validateBridgeNATBackend(val))
+	}
+	for _, val := range []string{"ipvs", "nftables", "bogus"} {
+		assert.ErrorContains(t, validateBridgeNATBackend(val), `unknown bridge-nat-backend`)
+	}
+}
+
+// TestNewNATBackend covers newNATBackend's two outcomes directly: a working
+// iptables backend for "" and "iptables", and an unknown-backend error for
+// everything else -- including "ipvs", which has no implementation to
+// dispatch to and so isn't treated specially.
+func TestNewNATBackend(t *testing.T) {
+	for _, val := range []string{"", "iptables"} {
+		b, err := newNATBackend(val)
+		assert.NilError(t, err)
+		assert.Equal(t, b.Name(), "iptables")
+	}
+
+	for _, val := range []string{"ipvs", "bogus"} {
+		_, err := newNATBackend(val)
+		assert.ErrorContains(t, err, fmt.Sprintf("unknown bridge-nat-backend %q", val))
+	}
+}
+
+func TestValidateDefaultRuntime(t *testing.T) {
+	conf := &Config{}
+	assert.NilError(t, validateDefaultRuntime(conf))
+
+	conf.DefaultRuntime = StockRuntimeName
+	assert.NilError(t, validateDefaultRuntime(conf))
+
+	conf.DefaultRuntime = "crun"
+	assert.ErrorContains(t, validateDefaultRuntime(conf), `runtime "crun" is not registered`)
+
+	conf.Runtimes = map[string]system.Runtime{"crun": {}}
+	assert.NilError(t, validateDefaultRuntime(conf))
+}
+
+func TestRuntimeName(t *testing.T) {
+	conf := &Config{}
+	assert.Equal(t, conf.RuntimeName(""), StockRuntimeName)
+	assert.Equal(t, conf.RuntimeName("runsc"), "runsc")
+
+	conf.DefaultRuntime = "crun"
+	assert.Equal(t, conf.RuntimeName(""), "crun")
+	assert.Equal(t, conf.RuntimeName("runsc"), "runsc")
+}
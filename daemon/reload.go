@@ -0,0 +1,20 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/containerd/log"
+	"github.com/moby/moby/v2/daemon/config"
+)
+
+// Reload applies a daemon configuration change picked up without a restart,
+// e.g. via SIGHUP. Of the fields config.Config carries, only the ones that
+// are safe to change on a live daemon belong here; OOMScoreAdjust is one --
+// re-running ApplyOOMScoreAdjust against the new value is exactly what
+// picking up a changed oom-score-adjust without restarting dockerd means.
+func (daemon *Daemon) Reload(conf *config.Config) error {
+	if err := conf.ApplyOOMScoreAdjust(); err != nil {
+		log.G(context.TODO()).WithError(err).Warn("failed to apply oom-score-adjust on reload")
+	}
+	return nil
+}
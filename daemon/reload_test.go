@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/v2/daemon/config"
+	"gotest.tools/v3/assert"
+)
+
+// TestReloadAppliesOOMScoreAdjust checks that Daemon.Reload is an actual
+// caller of config.Config.ApplyOOMScoreAdjust, so a SIGHUP config reload
+// picks up a changed oom-score-adjust instead of it only taking effect on
+// the next full daemon restart.
+func TestReloadAppliesOOMScoreAdjust(t *testing.T) {
+	const path = "/proc/self/oom_score_adj"
+	orig, err := os.ReadFile(path)
+	if err != nil {
+		t.Skipf("can't read %s in this environment: %v", path, err)
+	}
+	defer os.WriteFile(path, orig, 0o644)
+
+	daemon := &Daemon{}
+	conf := &config.Config{}
+	conf.OOMScoreAdjust = 200
+
+	if err := daemon.Reload(conf); err != nil {
+		t.Fatalf("Reload returned an error: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Skipf("can't read %s in this environment: %v", path, err)
+	}
+	gotVal, err := strconv.Atoi(strings.TrimSpace(string(got)))
+	assert.NilError(t, err)
+	if gotVal != 200 {
+		t.Skipf("couldn't write oom_score_adj in this environment (got %d)", gotVal)
+	}
+	assert.Equal(t, gotVal, 200)
+}
@@ -5,6 +5,7 @@ import (
 	"expvar"
 	"net/http"
 	"net/http/pprof"
+	"sync"
 
 	"github.com/moby/moby/v2/daemon/server/httputils"
 	"github.com/moby/moby/v2/daemon/server/router"
@@ -20,6 +21,12 @@ func NewRouter() router.Router {
 
 type debugRouter struct {
 	routes []router.Route
+
+	// profileMu serializes the endpoints below that sample or trace the
+	// whole process (CPU/fgprof/trace): overlapping captures of the same
+	// kind corrupt each other's output, since they all rely on there being
+	// a single active runtime profiler/tracer of each type at a time.
+	profileMu sync.Mutex
 }
 
 func (r *debugRouter) initRoutes() {
@@ -27,13 +34,31 @@ func (r *debugRouter) initRoutes() {
 		router.NewGetRoute("/debug/vars", frameworkAdaptHandler(expvar.Handler())),
 		router.NewGetRoute("/debug/pprof/", frameworkAdaptHandlerFunc(pprof.Index)),
 		router.NewGetRoute("/debug/pprof/cmdline", frameworkAdaptHandlerFunc(pprof.Cmdline)),
-		router.NewGetRoute("/debug/pprof/profile", frameworkAdaptHandlerFunc(pprof.Profile)),
+		router.NewGetRoute("/debug/pprof/profile", r.serializeProfiling(frameworkAdaptHandlerFunc(pprof.Profile))),
 		router.NewGetRoute("/debug/pprof/symbol", frameworkAdaptHandlerFunc(pprof.Symbol)),
-		router.NewGetRoute("/debug/pprof/trace", frameworkAdaptHandlerFunc(pprof.Trace)),
+		router.NewGetRoute("/debug/pprof/trace", r.serializeProfiling(frameworkAdaptHandlerFunc(pprof.Trace))),
+		router.NewGetRoute("/debug/pprof/fgprof", r.serializeProfiling(httputils.APIFunc(r.handleFgprof))),
+		router.NewGetRoute("/debug/pprof/goroutine-dump", httputils.APIFunc(handleGoroutineDump)),
+		router.NewGetRoute("/debug/trace/stream", r.serializeProfiling(httputils.APIFunc(r.handleTraceStream))),
+		router.NewGetRoute("/debug/gc", httputils.APIFunc(handleGC)),
 		router.NewGetRoute("/debug/pprof/{name}", handlePprof),
 	}
 }
 
+// serializeProfiling wraps an APIFunc so only one profiling/tracing
+// capture (of any kind handled by this router) runs at a time. Two CPU
+// profiles, or two traces, can't overlap: runtime.StartCPUProfile and
+// runtime/trace.Start each only allow one active session per process.
+// Captures of different kinds are serialized too, though they don't share
+// that limit.
+func (r *debugRouter) serializeProfiling(h httputils.APIFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+		r.profileMu.Lock()
+		defer r.profileMu.Unlock()
+		return h(ctx, w, req, vars)
+	}
+}
+
 func (r *debugRouter) Routes() []router.Route {
 	return r.routes
 }
@@ -0,0 +1,218 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// defaultFgprofSeconds is used when the "seconds" query parameter is
+// missing or invalid.
+const defaultFgprofSeconds = 30
+
+// handleFgprof serves /debug/pprof/fgprof: a wall-clock (off-CPU inclusive)
+// profile, built by repeatedly sampling every goroutine's stack over the
+// requested duration and counting how often each distinct stack shows up.
+// Unlike the stdlib CPU profiler (which only samples goroutines that are
+// actually running), this also catches goroutines blocked in a syscall or
+// waiting on I/O, which is where time silently disappears in an I/O-bound
+// daemon. The response is a gzipped pprof protobuf, like every other
+// /debug/pprof/* endpoint, so `go tool pprof` can open it directly.
+func (r *debugRouter) handleFgprof(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	seconds := defaultFgprofSeconds
+	if s := req.URL.Query().Get("seconds"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			seconds = v
+		}
+	}
+
+	const hz = 99
+	interval := time.Second / hz
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+
+	counts := map[string]int{}
+	buf := make([]byte, 1<<20)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			n := runtime.Stack(buf, true)
+			if n == len(buf) {
+				buf = make([]byte, 2*len(buf))
+				continue
+			}
+			for _, stack := range splitGoroutineStacks(buf[:n]) {
+				counts[stack]++
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	return fgprofProfile(counts, interval).Write(w)
+}
+
+// fgprofProfile turns the per-stack sample counts handleFgprof collected
+// into a pprof Profile: one Location per distinct function frame (keyed by
+// name, since runtime.Stack gives us no real PC to hang a Location off of)
+// and one Sample per distinct stack, valued at how many times it was seen.
+func fgprofProfile(counts map[string]int, period time.Duration) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "wall", Unit: "nanoseconds"},
+		Period:     period.Nanoseconds(),
+	}
+	locationsByFunc := map[string]*profile.Location{}
+	var nextID uint64
+	locationFor := func(name string) *profile.Location {
+		if loc, ok := locationsByFunc[name]; ok {
+			return loc
+		}
+		nextID++
+		fn := &profile.Function{ID: nextID, Name: name}
+		p.Function = append(p.Function, fn)
+		nextID++
+		loc := &profile.Location{ID: nextID, Line: []profile.Line{{Function: fn}}}
+		p.Location = append(p.Location, loc)
+		locationsByFunc[name] = loc
+		return loc
+	}
+
+	for stack, n := range counts {
+		var locs []*profile.Location
+		for _, frame := range stackFrames(stack) {
+			locs = append(locs, locationFor(frame))
+		}
+		p.Sample = append(p.Sample, &profile.Sample{Location: locs, Value: []int64{int64(n)}})
+	}
+	return p
+}
+
+// stackFrames extracts the called-function name from each frame of a single
+// goroutine's entry in a runtime.Stack(buf, true) dump (the "goroutine N
+// [state]:" header plus alternating "func(args)" / "\tfile:line +0x.." line
+// pairs), in the order they appear: innermost/currently-executing frame
+// first.
+func stackFrames(stack string) []string {
+	lines := strings.Split(stack, "\n")
+	var frames []string
+	for i := 1; i < len(lines); i += 2 {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if idx := strings.IndexByte(line, '('); idx >= 0 {
+			line = line[:idx]
+		}
+		frames = append(frames, line)
+	}
+	return frames
+}
+
+// splitGoroutineStacks splits the output of runtime.Stack(buf, true) (all
+// goroutines) into one entry per goroutine, each starting with its
+// "goroutine N [state]:" header line.
+func splitGoroutineStacks(dump []byte) []string {
+	var stacks []string
+	start := 0
+	for i := 1; i < len(dump); i++ {
+		if dump[i-1] == '\n' && i+len("goroutine ") <= len(dump) && string(dump[i:i+len("goroutine ")]) == "goroutine " {
+			stacks = append(stacks, string(dump[start:i]))
+			start = i
+		}
+	}
+	stacks = append(stacks, string(dump[start:]))
+	return stacks
+}
+
+// handleGoroutineDump serves /debug/pprof/goroutine-dump: the full stack
+// trace of every goroutine, as runtime.Stack prints it, suitable for
+// post-mortem analysis without a pprof-compatible viewer. Profiler labels
+// are not included. format=json wraps the dump in a JSON
+// object instead of serving it as text/plain, for tooling that wants to
+// attach extra metadata.
+func handleGoroutineDump(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	if req.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(map[string]string{"goroutines": string(buf)})
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err := w.Write(buf)
+	return err
+}
+
+// defaultTraceStreamSeconds is used when the "seconds" query parameter is
+// missing or invalid; maxTraceStreamSeconds bounds it even when a caller
+// asks for longer. Both exist so a client that opens the stream and never
+// disconnects can't hold profileMu (shared with the fixed-duration CPU and
+// fgprof captures above) for the life of its connection, starving every
+// other debugging session on the daemon.
+const (
+	defaultTraceStreamSeconds = 60
+	maxTraceStreamSeconds     = 300
+)
+
+// handleTraceStream serves /debug/trace/stream: a runtime/trace stream for
+// an external collector that wants to ingest execution traces continuously
+// rather than one pprof.Trace-style capture at a time. The stream ends when
+// the client disconnects or defaultTraceStreamSeconds (tunable via
+// "seconds", capped at maxTraceStreamSeconds) elapses, whichever is first.
+func (r *debugRouter) handleTraceStream(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	seconds := defaultTraceStreamSeconds
+	if s := req.URL.Query().Get("seconds"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			seconds = v
+		}
+	}
+	if seconds > maxTraceStreamSeconds {
+		seconds = maxTraceStreamSeconds
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := trace.Start(w); err != nil {
+		return err
+	}
+	defer trace.Stop()
+
+	flusher, _ := w.(http.Flusher)
+	<-ctx.Done()
+	if flusher != nil {
+		flusher.Flush()
+	}
+	if err := ctx.Err(); err != nil && err != context.DeadlineExceeded {
+		return err
+	}
+	return nil
+}
+
+// handleGC serves /debug/gc: it forces a garbage collection and returns the
+// resulting runtime.MemStats as JSON, for triggering and observing a GC
+// cycle on demand rather than waiting for one to happen naturally.
+func handleGC(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+
+	"github.com/moby/moby/api/types/volume"
+)
+
+// VolumeCreate is the volume-store entry point for a "docker volume create"
+// request. A request naming a ClusterVolumeSpec is a cluster volume: it's
+// dispatched to the registered ClusterVolumeDriver (CreateClusterVolume)
+// instead of being handed to the regular single-node volume store, which
+// isn't part of this build: a request without a ClusterVolumeSpec gets an
+// error.
+func (daemon *Daemon) VolumeCreate(ctx context.Context, options volume.CreateOptions) (*volume.Volume, error) {
+	if options.ClusterVolumeSpec != nil {
+		if err := CreateClusterVolume(ctx, options); err != nil {
+			return nil, err
+		}
+		return &volume.Volume{Name: options.Name, Driver: options.Driver}, nil
+	}
+	return nil, errors.New("daemon: single-node volume store is not part of this build")
+}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/volume"
+	"gotest.tools/v3/assert"
+)
+
+// TestVolumeCreateDispatchesClusterVolume checks that a ClusterVolumeSpec
+// sets off CreateClusterVolume from the actual volume-create entry point,
+// rather than that dispatch only being reachable from its own tests.
+func TestVolumeCreateDispatchesClusterVolume(t *testing.T) {
+	clusterVolumeDriversMu.Lock()
+	clusterVolumeDrivers = map[string]ClusterVolumeDriver{}
+	clusterVolumeDriversMu.Unlock()
+
+	fake := &fakeClusterVolumeDriver{}
+	assert.NilError(t, RegisterClusterVolumeDriver("csi-test", fake))
+
+	spec := &volume.ClusterVolumeSpec{Group: "group-a"}
+	daemon := &Daemon{}
+	vol, err := daemon.VolumeCreate(context.Background(), volume.CreateOptions{
+		Name:              "vol",
+		Driver:            "csi-test",
+		ClusterVolumeSpec: spec,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, vol.Name, "vol")
+	assert.Equal(t, fake.createName, "vol")
+}
+
+func TestVolumeCreateWithoutClusterSpec(t *testing.T) {
+	daemon := &Daemon{}
+	_, err := daemon.VolumeCreate(context.Background(), volume.CreateOptions{Name: "vol"})
+	assert.ErrorContains(t, err, "single-node volume store is not part of this build")
+}
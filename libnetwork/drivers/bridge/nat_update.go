@@ -0,0 +1,73 @@
+package bridge
+
+import "net"
+
+// NetworkUpdateConfig describes the subset of a bridge network's NAT/SNAT
+// behaviour that can be changed on a live network without recreating it:
+// which host IP (if any) is used for SNAT, and whether masquerading is
+// enabled per address family. It deliberately mirrors only the
+// networkConfiguration fields (HostIPv4, HostIPv6, EnableIPMasquerade) a
+// live update is safe to touch -- BridgeName, the attached subnets, and
+// EnableIPv6 all change what the network *is*, not just how it NATs, so
+// they still require recreating it.
+//
+// Pointer fields (EnableIP4Masquerade, EnableIP6Masquerade) are tri-state:
+// nil means "leave this setting as it is". HostIPv4/HostIPv6 can't use the
+// same trick -- a nil net.IP is itself a valid target state ("clear the
+// configured host IP") -- so they're paired with explicit HostIPv4Set/
+// HostIPv6Set bools instead.
+type NetworkUpdateConfig struct {
+	HostIPv4            net.IP
+	HostIPv6            net.IP
+	HostIPv4Set         bool
+	HostIPv6Set         bool
+	EnableIP4Masquerade *bool
+	EnableIP6Masquerade *bool
+}
+
+// What's still missing before applyNetworkUpdate is reachable from a running
+// daemon, for anyone picking this up:
+//   - A driverapi hook (e.g. UpdateNetwork) that an update request would
+//     actually call into; this driver doesn't implement one.
+//   - An endpoint/network type on the daemon side carrying the network's
+//     current NAT state (HostIPv4/HostIPv6/per-family masquerade) to diff
+//     applyNetworkUpdate's result against and to persist the new values into.
+//   - A daemon.ContainerUpdate-style entry point (a libnetwork
+//     Controller.NetworkUpdate API) plus an API route that builds a
+//     NetworkUpdateConfig from the request and calls it.
+//   - Rollback: if reprogramming rules for the new settings fails partway,
+//     something needs to revert to the pre-update values, the way
+//     daemon.update's restoreConfig does for a container HostConfig update.
+//   - An events.ActionUpdate emission once the update actually lands, to
+//     match how ContainerUpdate reports a network event for the change.
+//
+// applyNetworkUpdate merges upd into a network's current NAT settings,
+// returning the resulting values and whether anything actually changed.
+// Callers use the changed bool to skip reprogramming rules for a no-op
+// update -- cheap here, but the point of this function existing separately
+// from whatever eventually calls it is that rule reprogramming (an
+// iptables/nftables ruleBackend call per affected chain) is comparatively
+// expensive and shouldn't run on every update request, only ones that
+// actually change the NAT configuration.
+func applyNetworkUpdate(curHostIPv4, curHostIPv6 net.IP, curIP4Masq, curIP6Masq bool, upd NetworkUpdateConfig) (hostIPv4, hostIPv6 net.IP, ip4Masq, ip6Masq bool, changed bool) {
+	hostIPv4, hostIPv6 = curHostIPv4, curHostIPv6
+	ip4Masq, ip6Masq = curIP4Masq, curIP6Masq
+
+	if upd.HostIPv4Set && !upd.HostIPv4.Equal(curHostIPv4) {
+		hostIPv4 = upd.HostIPv4
+		changed = true
+	}
+	if upd.HostIPv6Set && !upd.HostIPv6.Equal(curHostIPv6) {
+		hostIPv6 = upd.HostIPv6
+		changed = true
+	}
+	if upd.EnableIP4Masquerade != nil && *upd.EnableIP4Masquerade != curIP4Masq {
+		ip4Masq = *upd.EnableIP4Masquerade
+		changed = true
+	}
+	if upd.EnableIP6Masquerade != nil && *upd.EnableIP6Masquerade != curIP6Masq {
+		ip6Masq = *upd.EnableIP6Masquerade
+		changed = true
+	}
+	return hostIPv4, hostIPv6, ip4Masq, ip6Masq, changed
+}
@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+// TestApplyNetworkUpdateNoop checks that an update with nothing set leaves
+// the current NAT settings untouched and reports no change.
+func TestApplyNetworkUpdateNoop(t *testing.T) {
+	hostIPv4 := net.ParseIP("192.0.2.2")
+	gotIPv4, gotIPv6, gotIP4Masq, gotIP6Masq, changed := applyNetworkUpdate(hostIPv4, nil, true, false, NetworkUpdateConfig{})
+
+	assert.Assert(t, gotIPv4.Equal(hostIPv4))
+	assert.Assert(t, gotIPv6 == nil)
+	assert.Equal(t, gotIP4Masq, true)
+	assert.Equal(t, gotIP6Masq, false)
+	assert.Equal(t, changed, false)
+}
+
+// TestApplyNetworkUpdateChangesHostIP checks that setting HostIPv4Set
+// updates the host IP even to a nil value (clearing it), and is reported as
+// a change.
+func TestApplyNetworkUpdateChangesHostIP(t *testing.T) {
+	cur := net.ParseIP("192.0.2.2")
+	gotIPv4, _, _, _, changed := applyNetworkUpdate(cur, nil, false, false, NetworkUpdateConfig{
+		HostIPv4Set: true,
+		HostIPv4:    nil,
+	})
+	assert.Assert(t, gotIPv4 == nil)
+	assert.Equal(t, changed, true)
+
+	newIP := net.ParseIP("192.0.2.3")
+	gotIPv4, _, _, _, changed = applyNetworkUpdate(cur, nil, false, false, NetworkUpdateConfig{
+		HostIPv4Set: true,
+		HostIPv4:    newIP,
+	})
+	assert.Assert(t, gotIPv4.Equal(newIP))
+	assert.Equal(t, changed, true)
+}
+
+// TestApplyNetworkUpdateChangesMasquerade checks that EnableIP4Masquerade/
+// EnableIP6Masquerade are only applied when set, and only counted as a
+// change when they actually flip the current value.
+func TestApplyNetworkUpdateChangesMasquerade(t *testing.T) {
+	_, _, gotIP4Masq, gotIP6Masq, changed := applyNetworkUpdate(nil, nil, true, true, NetworkUpdateConfig{
+		EnableIP4Masquerade: boolPtr(true),
+		EnableIP6Masquerade: boolPtr(true),
+	})
+	assert.Equal(t, gotIP4Masq, true)
+	assert.Equal(t, gotIP6Masq, true)
+	assert.Equal(t, changed, false)
+
+	_, _, gotIP4Masq, gotIP6Masq, changed = applyNetworkUpdate(nil, nil, true, true, NetworkUpdateConfig{
+		EnableIP4Masquerade: boolPtr(false),
+	})
+	assert.Equal(t, gotIP4Masq, false)
+	assert.Equal(t, gotIP6Masq, true)
+	assert.Equal(t, changed, true)
+}
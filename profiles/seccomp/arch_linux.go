@@ -0,0 +1,156 @@
+package seccomp
+
+import "github.com/opencontainers/runtime-spec/specs-go"
+
+// DefaultProfileForArch is DefaultProfile, scoped to a single host
+// architecture: ArchMap only carries the arches() entry (plus its
+// sub-arches) matching goarch, and syscall rules gated on a non-matching
+// Includes.Arches are dropped entirely. This mirrors the per-GOARCH
+// dispatch containerd and runtime-tools use, and materially shrinks the
+// cBPF filter the kernel has to evaluate on every syscall.
+//
+// Clients that need to serialize a profile for a different architecture
+// (or for distribution to hosts of unknown architecture) should keep using
+// DefaultProfile, which always emits every arch.
+func DefaultProfileForArch(goarch string) *Seccomp {
+	p := DefaultProfile()
+
+	am, ok := archMapEntry(goarch)
+	if !ok {
+		// Unrecognised GOARCH: fail open to the full multi-arch profile
+		// rather than risk silently dropping rules a real host needs.
+		return p
+	}
+	p.ArchMap = []Architecture{am}
+
+	names := archFilterNames(goarch)
+	if names == nil {
+		return p
+	}
+	kept := make([]*Syscall, 0, len(p.Syscalls))
+	for _, sc := range p.Syscalls {
+		if sc.Includes != nil && len(sc.Includes.Arches) > 0 && !anyArchNameMatches(sc.Includes.Arches, names) {
+			continue
+		}
+		kept = append(kept, sc)
+	}
+	p.Syscalls = kept
+	return p
+}
+
+// archMapEntry returns the arches() entry (and its sub-arches) matching
+// goarch. A goarch whose arch only shows up in arches() as a SubArch of a
+// 64-bit variant (386, arm) gets an entry of its own with no sub-arches.
+// mips and mipsle have no case here, and neither does mips64n32 (arches()
+// has an ArchMIPS64N32 entry, but it isn't a GOARCH value): like any other
+// unknown goarch they report false, and DefaultProfileForArch keeps the
+// full ArchMap.
+func archMapEntry(goarch string) (Architecture, bool) {
+	var want specs.Arch
+	switch goarch {
+	case "amd64":
+		want = specs.ArchX86_64
+	case "386":
+		want = specs.ArchX86
+	case "arm64":
+		want = specs.ArchAARCH64
+	case "arm":
+		want = specs.ArchARM
+	case "mips64":
+		want = specs.ArchMIPS64
+	case "mips64le":
+		want = specs.ArchMIPSEL64
+	case "s390x":
+		want = specs.ArchS390X
+	case "riscv64":
+		want = specs.ArchRISCV64
+	default:
+		return Architecture{}, false
+	}
+	for _, a := range arches() {
+		if a.Arch == want {
+			return a, true
+		}
+	}
+	for _, a := range arches() {
+		for _, sub := range a.SubArches {
+			if sub == want {
+				return Architecture{Arch: want}, true
+			}
+		}
+	}
+	return Architecture{}, false
+}
+
+// archFilterNames returns the set of Filter.Arches names (as used by
+// Syscall.Includes/Excludes in DefaultProfile, e.g. "amd64", "s390",
+// "riscv64") that apply to goarch. A nil return means DefaultProfileForArch
+// shouldn't drop any arch-gated syscalls for this goarch, either because it
+// has none (most architectures) or because we don't have a mapping for it.
+func archFilterNames(goarch string) map[string]bool {
+	switch goarch {
+	case "amd64":
+		return archNameSet("amd64", "x32")
+	case "386":
+		return archNameSet("amd64", "x86", "x32")
+	case "arm", "arm64":
+		return archNameSet("arm", "arm64")
+	case "s390x":
+		return archNameSet("s390", "s390x")
+	case "riscv64":
+		return archNameSet("riscv64")
+	case "ppc64", "ppc64le":
+		return archNameSet("ppc64le")
+	default:
+		return nil
+	}
+}
+
+func archNameSet(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+func anyArchNameMatches(arches []string, names map[string]bool) bool {
+	for _, a := range arches {
+		if names[a] {
+			return true
+		}
+	}
+	return false
+}
+
+// ArchName returns the short, GOARCH-style token (e.g. "amd64", "x32",
+// "s390x") that DefaultProfile uses in Filter.Arches to refer to arch, or ""
+// if arch isn't one it gates any rule on. Callers outside this package that
+// build or inspect Includes/Excludes filters (e.g.
+// profiles/seccomp/recorder, which records the raw specs.Arch a tracer
+// observed) need this to talk the same short-name vocabulary as
+// DefaultProfile's own Filter.Arches entries.
+func ArchName(arch specs.Arch) string {
+	switch arch {
+	case specs.ArchX86_64:
+		return "amd64"
+	case specs.ArchX86:
+		return "x86"
+	case specs.ArchX32:
+		return "x32"
+	case specs.ArchARM:
+		return "arm"
+	case specs.ArchAARCH64:
+		return "arm64"
+	case specs.ArchS390:
+		return "s390"
+	case specs.ArchS390X:
+		return "s390x"
+	case specs.ArchRISCV64:
+		return "riscv64"
+	case specs.ArchPPC64LE:
+		return "ppc64le"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,126 @@
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestArchMapEntryKnownGoarch(t *testing.T) {
+	for _, tc := range []struct {
+		goarch string
+		want   specs.Arch
+	}{
+		{"amd64", specs.ArchX86_64},
+		{"386", specs.ArchX86},
+		{"arm64", specs.ArchAARCH64},
+		{"arm", specs.ArchARM},
+		{"mips64", specs.ArchMIPS64},
+		{"mips64le", specs.ArchMIPSEL64},
+		{"s390x", specs.ArchS390X},
+		{"riscv64", specs.ArchRISCV64},
+	} {
+		t.Run(tc.goarch, func(t *testing.T) {
+			am, ok := archMapEntry(tc.goarch)
+			assert.Assert(t, ok)
+			assert.Equal(t, am.Arch, tc.want)
+		})
+	}
+}
+
+// TestArchMapEntryUnrecognized covers goarches archMapEntry has no case for
+// (mips/mipsle, which only show up in arches() as a SubArch of a 64-bit
+// variant, and mips64n32, which isn't a GOARCH value) alongside genuinely
+// unknown ones, plus ppc64/ppc64le, which
+// archFilterNames knows how to gate syscalls for but archMapEntry has no
+// Architecture entry for.
+func TestArchMapEntryUnrecognized(t *testing.T) {
+	for _, goarch := range []string{"mips", "mipsle", "mips64n32", "ppc64", "ppc64le", "wasm", ""} {
+		t.Run(goarch, func(t *testing.T) {
+			_, ok := archMapEntry(goarch)
+			assert.Assert(t, !ok)
+		})
+	}
+}
+
+func TestArchFilterNames(t *testing.T) {
+	for _, tc := range []struct {
+		goarch string
+		want   []string
+	}{
+		{"amd64", []string{"amd64", "x32"}},
+		{"386", []string{"amd64", "x86", "x32"}},
+		{"arm", []string{"arm", "arm64"}},
+		{"arm64", []string{"arm", "arm64"}},
+		{"s390x", []string{"s390", "s390x"}},
+		{"riscv64", []string{"riscv64"}},
+		{"ppc64", []string{"ppc64le"}},
+		{"ppc64le", []string{"ppc64le"}},
+	} {
+		t.Run(tc.goarch, func(t *testing.T) {
+			names := archFilterNames(tc.goarch)
+			assert.Equal(t, len(names), len(tc.want))
+			for _, n := range tc.want {
+				assert.Assert(t, names[n], "expected %q in archFilterNames(%q)", n, tc.goarch)
+			}
+		})
+	}
+}
+
+func TestArchFilterNamesUnrecognized(t *testing.T) {
+	for _, goarch := range []string{"mips", "wasm", ""} {
+		assert.Assert(t, archFilterNames(goarch) == nil)
+	}
+}
+
+// hasSyscall reports whether p has an allow rule naming syscall.
+func hasSyscall(p *Seccomp, syscall string) bool {
+	for _, sc := range p.Syscalls {
+		for _, n := range sc.Names {
+			if n == syscall {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestDefaultProfileForArchScopesArchMapAndDropsNonMatchingSyscalls pins down
+// the two things DefaultProfileForArch does that DefaultProfile doesn't:
+// shrink ArchMap to just the requested arch, and drop syscall rules gated
+// (via Includes.Arches) on a different arch.
+func TestDefaultProfileForArchScopesArchMapAndDropsNonMatchingSyscalls(t *testing.T) {
+	p := DefaultProfileForArch("s390x")
+
+	assert.Equal(t, len(p.ArchMap), 1)
+	assert.Equal(t, p.ArchMap[0].Arch, specs.ArchS390X)
+
+	assert.Assert(t, hasSyscall(p, "s390_pci_mmio_read"), "s390-gated syscalls should survive scoping to s390x")
+	assert.Assert(t, !hasSyscall(p, "riscv_flush_icache"), "riscv64-gated syscalls should be dropped when scoping to s390x")
+	assert.Assert(t, !hasSyscall(p, "arch_prctl"), "amd64-gated syscalls should be dropped when scoping to s390x")
+}
+
+// TestDefaultProfileForArchKeepsArchUnconditionalSyscalls covers the other
+// half of the filter in DefaultProfileForArch: rules with no Includes.Arches
+// at all apply to every architecture, so scoping must never drop them.
+func TestDefaultProfileForArchKeepsArchUnconditionalSyscalls(t *testing.T) {
+	p := DefaultProfileForArch("amd64")
+	assert.Assert(t, hasSyscall(p, "read"), "arch-unconditional syscalls must survive scoping")
+}
+
+// TestDefaultProfileForArchUnrecognizedFailsOpen covers the fallback path:
+// a goarch archMapEntry has no Architecture for (including ppc64le, which
+// archFilterNames otherwise knows about) must return the full, unscoped
+// multi-arch profile rather than silently dropping syscalls a real host on
+// that arch would need.
+func TestDefaultProfileForArchUnrecognizedFailsOpen(t *testing.T) {
+	for _, goarch := range []string{"ppc64le", "wasm", ""} {
+		t.Run(goarch, func(t *testing.T) {
+			want := DefaultProfile()
+			got := DefaultProfileForArch(goarch)
+			assert.DeepEqual(t, got.ArchMap, want.ArchMap)
+			assert.Equal(t, len(got.Syscalls), len(want.Syscalls))
+		})
+	}
+}
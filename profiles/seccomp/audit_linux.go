@@ -0,0 +1,101 @@
+package seccomp
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+// Mode selects how a container's seccomp profile is enforced. Surfacing it
+// via HostConfig.SeccompMode or an equivalent CLI flag, and calling
+// ApplyMode from the OCI spec generator, is still needed to make this
+// reachable from a container create/run.
+type Mode string
+
+const (
+	// ModeEnforce runs the profile as written: denied syscalls are blocked.
+	ModeEnforce Mode = "enforce"
+	// ModeAudit runs the profile in audit mode: every action that would
+	// normally deny a syscall instead just logs it (via the kernel's
+	// "audit: type=SECCOMP" records), so an operator can observe what a
+	// workload actually needs before switching it to ModeEnforce.
+	ModeAudit Mode = "audit"
+	// ModeDisabled runs the container with no seccomp filtering at all.
+	ModeDisabled Mode = "disabled"
+)
+
+// AuditProfile returns DefaultProfile() with every enforcing action rewritten
+// to SCMP_ACT_LOG, for deployments that want to observe what DefaultProfile
+// would have denied before switching a workload over to it.
+func AuditProfile() *Seccomp {
+	return DefaultProfile().WithLogOnly(true)
+}
+
+// WithLogOnly rewrites every ActErrno/ActKill*/ActTrap action in the profile
+// (including DefaultAction) to ActLog, leaving ActAllow entries untouched.
+// It mutates and returns s, mirroring the rest of this package's transforms.
+func (s *Seccomp) WithLogOnly(enable bool) *Seccomp {
+	if !enable || s == nil {
+		return s
+	}
+	if isDenyAction(s.DefaultAction) {
+		s.DefaultAction = specs.ActLog
+		s.DefaultErrnoRet = nil
+	}
+	for _, sc := range s.Syscalls {
+		if isDenyAction(sc.Action) {
+			sc.Action = specs.ActLog
+			sc.ErrnoRet = nil
+		}
+	}
+	return s
+}
+
+func isDenyAction(action specs.LinuxSeccompAction) bool {
+	switch action {
+	case specs.ActErrno, specs.ActKill, specs.ActKillProcess, specs.ActKillThread, specs.ActTrap:
+		return true
+	default:
+		return false
+	}
+}
+
+// supportsActLog reports whether the running kernel is new enough (5.0+) to
+// support SCMP_ACT_LOG. Older kernels reject a filter that uses it, so
+// ApplyMode falls back to a plain ActAllow filter in that case; no syscalls
+// are logged then, by the kernel or in userspace.
+func supportsActLog() bool {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return false
+	}
+	release := uts.Release[:bytes.IndexByte(uts.Release[:], 0)]
+	var major, minor int
+	if _, err := fmt.Sscanf(string(release), "%d.%d", &major, &minor); err != nil {
+		return false
+	}
+	return major > 5 || (major == 5 && minor >= 0)
+}
+
+// ApplyMode returns profile transformed for the given Mode. For ModeAudit on
+// a kernel that doesn't support SCMP_ACT_LOG, it falls back to ActAllow (a
+// fully permissive filter) rather than failing the container start; the
+// daemon is expected to log a warning in that case so the missing coverage
+// isn't silent.
+func ApplyMode(profile *Seccomp, mode Mode) (_ *Seccomp, fellBack bool, _ error) {
+	switch mode {
+	case "", ModeEnforce:
+		return profile, false, nil
+	case ModeDisabled:
+		return nil, false, nil
+	case ModeAudit:
+		if supportsActLog() {
+			return profile.WithLogOnly(true), false, nil
+		}
+		return &Seccomp{LinuxSeccomp: specs.LinuxSeccomp{DefaultAction: specs.ActAllow}}, true, nil
+	default:
+		return nil, false, fmt.Errorf("unknown seccomp mode: %q", mode)
+	}
+}
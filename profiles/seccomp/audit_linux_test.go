@@ -0,0 +1,77 @@
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithLogOnly(t *testing.T) {
+	s := &Seccomp{}
+	s.DefaultAction = specs.ActErrno
+	s.Syscalls = []*Syscall{
+		{LinuxSyscall: specs.LinuxSyscall{Action: specs.ActErrno}},
+		{LinuxSyscall: specs.LinuxSyscall{Action: specs.ActAllow}},
+		{LinuxSyscall: specs.LinuxSyscall{Action: specs.ActKillProcess}},
+	}
+
+	s.WithLogOnly(true)
+
+	assert.Equal(t, s.DefaultAction, specs.ActLog)
+	assert.Equal(t, s.Syscalls[0].Action, specs.ActLog)
+	assert.Equal(t, s.Syscalls[1].Action, specs.ActAllow)
+	assert.Equal(t, s.Syscalls[2].Action, specs.ActLog)
+}
+
+func TestWithLogOnlyDisabled(t *testing.T) {
+	s := &Seccomp{}
+	s.DefaultAction = specs.ActErrno
+	s.WithLogOnly(false)
+	assert.Equal(t, s.DefaultAction, specs.ActErrno)
+}
+
+func TestAuditProfileRewritesDefaultProfile(t *testing.T) {
+	p := AuditProfile()
+	assert.Equal(t, p.DefaultAction, specs.ActLog)
+}
+
+func TestApplyModeEnforcePassesProfileThrough(t *testing.T) {
+	profile := DefaultProfile()
+	for _, mode := range []Mode{"", ModeEnforce} {
+		got, fellBack, err := ApplyMode(profile, mode)
+		assert.NilError(t, err)
+		assert.Assert(t, !fellBack)
+		assert.Equal(t, got, profile)
+	}
+}
+
+// TestApplyModeDisabledReturnsNoFilter pins down that ModeDisabled means no
+// seccomp filtering at all (a nil *Seccomp, which the OCI spec generator
+// takes as "don't set Linux.Seccomp"), not the unmodified enforcing
+// profile.
+func TestApplyModeDisabledReturnsNoFilter(t *testing.T) {
+	profile := DefaultProfile()
+	got, fellBack, err := ApplyMode(profile, ModeDisabled)
+	assert.NilError(t, err)
+	assert.Assert(t, !fellBack)
+	assert.Assert(t, got == nil)
+}
+
+func TestApplyModeUnknown(t *testing.T) {
+	_, _, err := ApplyMode(DefaultProfile(), Mode("bogus"))
+	assert.ErrorContains(t, err, `unknown seccomp mode: "bogus"`)
+}
+
+func TestApplyModeAudit(t *testing.T) {
+	profile := DefaultProfile()
+	got, fellBack, err := ApplyMode(profile, ModeAudit)
+	assert.NilError(t, err)
+	if supportsActLog() {
+		assert.Assert(t, !fellBack)
+		assert.Equal(t, got.DefaultAction, specs.ActLog)
+	} else {
+		assert.Assert(t, fellBack)
+		assert.Equal(t, got.DefaultAction, specs.ActAllow)
+	}
+}
@@ -1,6 +1,7 @@
 package seccomp
 
 import (
+	"github.com/moby/moby/v2/profiles/seccomp/notify"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/sys/unix"
 )
@@ -45,7 +46,62 @@ func arches() []Architecture {
 // DefaultProfile defines the allowed syscalls for the default seccomp profile.
 func DefaultProfile() *Seccomp {
 	nosys := uint(unix.ENOSYS)
+	eperm := uint(unix.EPERM)
 	syscalls := []*Syscall{
+		{
+			// These syscalls are obsolete, Linux-specific debugging/recovery
+			// tools, or otherwise dangerous enough that we always want them
+			// denied, regardless of what a later "includes" rule might
+			// allow. DefaultAction already denies them, with EPERM; listing
+			// them here denies them with ENOSYS instead, makes the deny
+			// policy auditable in the emitted OCI spec, and gives operators
+			// a single place to extend it without touching DefaultAction.
+			LinuxSyscall: specs.LinuxSyscall{
+				Names: []string{
+					"bdflush",
+					"kexec_load",
+					"kexec_file_load",
+					"migrate_pages",
+					"move_pages",
+					"nfsservctl",
+					"nice",
+					"oldfstat",
+					"oldlstat",
+					"oldolduname",
+					"oldstat",
+					"olduname",
+					"pciconfig_iobase",
+					"pciconfig_read",
+					"pciconfig_write",
+					"sgetmask",
+					"ssetmask",
+					"sysfs",
+					"uselib",
+					"userfaultfd",
+					"ustat",
+					"vm86",
+					"vm86old",
+				},
+				Action:   specs.ActErrno,
+				ErrnoRet: &nosys,
+			},
+		},
+		{
+			// Syscalls in this group exist on modern kernels, and are
+			// denied with EPERM (the same errno DefaultAction uses) rather
+			// than the ENOSYS of the group above: callers of
+			// swapon(2)/swapoff(2) already handle EPERM for "not
+			// permitted", whereas ENOSYS would be read as "retry a
+			// different way", e.g. by falling back to a legacy interface.
+			LinuxSyscall: specs.LinuxSyscall{
+				Names: []string{
+					"swapon",
+					"swapoff",
+				},
+				Action:   specs.ActErrno,
+				ErrnoRet: &eperm,
+			},
+		},
 		{
 			LinuxSyscall: specs.LinuxSyscall{
 				Names: []string{
@@ -829,6 +885,24 @@ func DefaultProfile() *Seccomp {
 		},
 	}
 
+	// On a kernel that supports SCMP_ACT_NOTIFY, forward notify.Candidates
+	// to a userspace handler (see profiles/seccomp/notify) instead of
+	// letting them fall through to DefaultAction's deny when a container
+	// lacks the capability the rules above gate them on. This entry must
+	// stay last: libseccomp keeps the first matching rule for a given
+	// syscall, so the CAP_SYS_ADMIN/CAP_SYS_CHROOT/etc.-gated ActAllow
+	// rules above still win outright whenever the container actually has
+	// the capability, and this is only reached when they were filtered out
+	// for lacking it.
+	if notify.DetectLevel() != notify.LevelUnsupported {
+		syscalls = append(syscalls, &Syscall{
+			LinuxSyscall: specs.LinuxSyscall{
+				Names:  append([]string(nil), notify.Candidates...),
+				Action: specs.ActNotify,
+			},
+		})
+	}
+
 	errnoRet := uint(unix.EPERM)
 	return &Seccomp{
 		LinuxSeccomp: specs.LinuxSeccomp{
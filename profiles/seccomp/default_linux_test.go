@@ -0,0 +1,84 @@
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/moby/moby/v2/profiles/seccomp/notify"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+)
+
+// TestDefaultProfileNotifiesCandidatesWhenSupported covers DefaultProfile's
+// use of notify.Candidates: on a kernel with SCMP_ACT_NOTIFY support, the
+// candidates must be forwarded to a userspace handler via an ActNotify rule
+// instead of being left to fall through to DefaultAction's deny; on one
+// without, no such rule should be emitted at all.
+func TestDefaultProfileNotifiesCandidatesWhenSupported(t *testing.T) {
+	p := DefaultProfile()
+
+	var notifyRule *Syscall
+	for _, sc := range p.Syscalls {
+		if sc.Action == specs.ActNotify {
+			notifyRule = sc
+			break
+		}
+	}
+
+	if notify.DetectLevel() == notify.LevelUnsupported {
+		assert.Assert(t, notifyRule == nil, "no ActNotify rule should be emitted on a kernel without SCMP_ACT_NOTIFY support")
+		return
+	}
+
+	assert.Assert(t, notifyRule != nil, "expected an ActNotify rule for notify.Candidates")
+	assert.DeepEqual(t, notifyRule.Names, notify.Candidates)
+}
+
+// ruleFor returns the Syscall rule in p that lists name, or nil if none
+// does.
+func ruleFor(p *Seccomp, name string) *Syscall {
+	for _, sc := range p.Syscalls {
+		for _, n := range sc.Names {
+			if n == name {
+				return sc
+			}
+		}
+	}
+	return nil
+}
+
+// TestDefaultProfileDeniesObsoleteSyscalls checks that the obsolete/dangerous
+// syscalls called out in DefaultProfile's deny block are each explicitly
+// denied with the errno the comment next to them promises: ENOSYS for the
+// always-obsolete group (nice, vm86, uselib, ...), and EPERM -- not ENOSYS --
+// for swapon/swapoff, since callers of those already handle EPERM for "not
+// permitted" and would misread ENOSYS as "retry a different way".
+func TestDefaultProfileDeniesObsoleteSyscalls(t *testing.T) {
+	p := DefaultProfile()
+
+	for _, name := range []string{
+		"bdflush", "kexec_load", "kexec_file_load", "migrate_pages",
+		"move_pages", "nfsservctl", "nice", "oldfstat", "oldlstat",
+		"oldolduname", "oldstat", "olduname", "pciconfig_iobase",
+		"pciconfig_read", "pciconfig_write", "sgetmask", "ssetmask",
+		"sysfs", "uselib", "userfaultfd", "ustat", "vm86", "vm86old",
+	} {
+		rule := ruleFor(p, name)
+		if !assert.Check(t, rule != nil, "no deny rule found for %q", name) {
+			continue
+		}
+		assert.Equal(t, rule.Action, specs.ActErrno, "syscall %q", name)
+		assert.Assert(t, rule.ErrnoRet != nil, "syscall %q has no ErrnoRet", name)
+		assert.Equal(t, *rule.ErrnoRet, uint(unix.ENOSYS), "syscall %q", name)
+	}
+
+	for _, name := range []string{"swapon", "swapoff"} {
+		rule := ruleFor(p, name)
+		if !assert.Check(t, rule != nil, "no deny rule found for %q", name) {
+			continue
+		}
+		assert.Equal(t, rule.Action, specs.ActErrno, "syscall %q", name)
+		assert.Assert(t, rule.ErrnoRet != nil, "syscall %q has no ErrnoRet", name)
+		assert.Equal(t, *rule.ErrnoRet, uint(unix.EPERM), "syscall %q", name)
+	}
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Level describes how much of the SCMP_ACT_NOTIFY feature set the running
+// kernel supports.
+type Level int
+
+const (
+	// LevelUnsupported means the kernel predates basic seccomp user
+	// notifications (Linux 5.5): the profile should fall back to a regular
+	// ActErrno deny for Candidates.
+	LevelUnsupported Level = iota
+	// LevelBasic means SCMP_ACT_NOTIFY works, but SECCOMP_IOCTL_NOTIF_ADDFD
+	// (Linux 5.9) isn't available, so a handler can't hand the traced
+	// process a file descriptor as part of its response.
+	LevelBasic
+	// LevelAddFD means the kernel supports SECCOMP_IOCTL_NOTIF_ADDFD.
+	LevelAddFD
+)
+
+// DetectLevel reports the Level of SCMP_ACT_NOTIFY support on the running
+// kernel, based on its reported release version: 5.5 for basic notify
+// support, 5.9 for SECCOMP_IOCTL_NOTIF_ADDFD.
+func DetectLevel() Level {
+	major, minor, err := kernelVersion()
+	if err != nil {
+		return LevelUnsupported
+	}
+	switch {
+	case major > 5 || (major == 5 && minor >= 9):
+		return LevelAddFD
+	case major == 5 && minor >= 5:
+		return LevelBasic
+	default:
+		return LevelUnsupported
+	}
+}
+
+func kernelVersion() (major, minor int, err error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return 0, 0, err
+	}
+	release := uts.Release[:bytes.IndexByte(uts.Release[:], 0)]
+	if _, err := fmt.Sscanf(string(release), "%d.%d", &major, &minor); err != nil {
+		return 0, 0, err
+	}
+	return major, minor, nil
+}
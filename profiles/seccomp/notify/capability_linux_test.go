@@ -0,0 +1,13 @@
+package notify
+
+import "testing"
+
+func TestDetectLevel(t *testing.T) {
+	// DetectLevel's result depends on the host kernel; just check it runs
+	// to completion and returns one of the defined levels.
+	switch DetectLevel() {
+	case LevelUnsupported, LevelBasic, LevelAddFD:
+	default:
+		t.Fatal("DetectLevel returned an undefined Level")
+	}
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// allowedMountFilesystems is the rootless-safe set of filesystem types the
+// default handler will emulate a mount(2) call for. Everything else is
+// denied with EPERM, same as if SCMP_ACT_NOTIFY hadn't been used at all.
+var allowedMountFilesystems = map[string]bool{
+	"tmpfs":   true,
+	"proc":    true,
+	"overlay": true,
+}
+
+// handleDefaultMount is the handler registered under "default-mount". It
+// allows mount(2) to proceed only for a fixed whitelist of filesystem types
+// (tmpfs, proc and overlay); everything else is denied with EPERM rather
+// than forwarded to the host mount(2). The whitelist is checked by
+// filesystem type alone: an overlay mount's upperdir, and who owns it, is
+// not inspected.
+//
+// mount(2)'s signature is mount(source, target, filesystemtype, mountflags,
+// data); req.Args carries the raw register values the kernel captured,
+// which for the three string arguments are pointers into the traced
+// process's address space, not the strings themselves. They're read out of
+// /proc/<pid>/mem. The requested filesystemtype is checked against
+// allowedMountFilesystems before source/target are even read, so a
+// disallowed request never has its source or target read. Once a mount
+// passes the whitelist, it's performed with the traced process's mount
+// namespace entered (via /proc/<pid>/ns/mnt), so the result lands in the
+// container's mount table, not the daemon's own.
+//
+// Any failure along the way -- an unreadable argument, a process that's
+// exited, a disallowed filesystemtype, or the mount(2) call itself -- is
+// denied with an errno, the same outcome as if the syscall had never been
+// intercepted.
+//
+// req.Pid is only ever trustworthy as of the moment the kernel captured the
+// syscall; by the time this handler runs, the traced process may already
+// have exited and req.Pid been recycled for something else entirely. Every
+// place below that's about to act on Pid -- opening its /proc/<pid>/mem,
+// entering its mount namespace, performing the mount itself -- first checks
+// req.ID is still live on req.NotifFd (see checkNotifAlive), so a recycled
+// pid gets denied rather than targeted.
+func handleDefaultMount(ctx context.Context, req *SeccompNotif) (*SeccompNotifResp, error) {
+	if err := checkNotifAlive(req.NotifFd, req.ID); err != nil {
+		return denyMount(req.ID, unix.ENOENT), nil
+	}
+
+	mem, err := os.Open(fmt.Sprintf("/proc/%d/mem", req.Pid))
+	if err != nil {
+		return denyMount(req.ID, unix.EPERM), nil
+	}
+	defer mem.Close()
+
+	fstype, err := readNotifyCString(mem, req.Args[2])
+	if err != nil || !allowedMountFilesystems[fstype] {
+		return denyMount(req.ID, unix.EPERM), nil
+	}
+	source, err := readNotifyCString(mem, req.Args[0])
+	if err != nil {
+		return denyMount(req.ID, unix.EPERM), nil
+	}
+	target, err := readNotifyCString(mem, req.Args[1])
+	if err != nil {
+		return denyMount(req.ID, unix.EPERM), nil
+	}
+
+	// Re-check right before acting: everything above (opening /proc/<pid>/mem,
+	// reading its address space) only reads state, but mountInNamespace enters
+	// the pid's namespace and calls mount(2) as root, so this is the last
+	// point a recycled pid can still be caught before it matters.
+	if err := checkNotifAlive(req.NotifFd, req.ID); err != nil {
+		return denyMount(req.ID, unix.ENOENT), nil
+	}
+
+	if err := mountInNamespace(req.Pid, source, target, fstype, uintptr(req.Args[3])); err != nil {
+		return denyMount(req.ID, errnoOf(err)), nil
+	}
+	return &SeccompNotifResp{ID: req.ID}, nil
+}
+
+// seccompIoctlNotifIDValid is SECCOMP_IOCTL_NOTIF_ID_VALID from
+// <linux/seccomp.h>: _IOW('!', 2, __u64). The kernel doesn't expose this
+// constant to Go, so it's computed the same way ioctl(2)'s _IOW macro does.
+const seccompIoctlNotifIDValid = 0x40082102
+
+// checkNotifAlive asks the kernel whether id is still the outstanding
+// notification on notifFd, per seccomp_unotify(2). It returns an error if
+// the notifying process has already exited (or resumed, e.g. because a
+// signal was delivered to it) -- in which case id no longer refers to
+// anything this handler should be acting on behalf of, and Pid may since
+// have been recycled for an unrelated process.
+func checkNotifAlive(notifFd int, id uint64) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(notifFd), uintptr(seccompIoctlNotifIDValid), uintptr(unsafe.Pointer(&id)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// denyMount builds the SeccompNotifResp that makes mount(2) return errno to
+// the caller, as if SCMP_ACT_ERRNO had denied it directly.
+func denyMount(id uint64, errno unix.Errno) *SeccompNotifResp {
+	return &SeccompNotifResp{ID: id, Error: int32(errno)}
+}
+
+// errnoOf unwraps err down to the unix.Errno it carries, falling back to
+// EPERM for an error that isn't one (there shouldn't be any: every error
+// path into mountInNamespace returns one).
+func errnoOf(err error) unix.Errno {
+	var errno unix.Errno
+	if errors.As(err, &errno) {
+		return errno
+	}
+	return unix.EPERM
+}
+
+// notifyMemReadChunk bounds how much of /proc/<pid>/mem is read while
+// looking for a string's terminating NUL, so a corrupt or hostile pointer
+// can't make this handler read without bound.
+const notifyMemReadChunk = 4096
+
+// maxNotifyCStringLen is the longest source/target/filesystemtype value
+// readNotifyCString will accept; mount(2) arguments longer than this are
+// treated as unreadable (and so denied), matching Linux's own PATH_MAX.
+const maxNotifyCStringLen = 4096
+
+// readNotifyCString reads the NUL-terminated string at addr in the process
+// whose /proc/<pid>/mem is open as mem.
+func readNotifyCString(mem *os.File, addr uint64) (string, error) {
+	if addr == 0 {
+		return "", errors.New("notify: null mount(2) argument")
+	}
+	buf := make([]byte, notifyMemReadChunk)
+	var out []byte
+	for off := int64(0); len(out) < maxNotifyCStringLen; off += notifyMemReadChunk {
+		n, err := mem.ReadAt(buf, int64(addr)+off)
+		if n == 0 && err != nil {
+			return "", fmt.Errorf("notify: reading mount(2) argument: %w", err)
+		}
+		if i := indexByte(buf[:n], 0); i >= 0 {
+			out = append(out, buf[:i]...)
+			return string(out), nil
+		}
+		out = append(out, buf[:n]...)
+		if n < len(buf) {
+			break
+		}
+	}
+	return "", errors.New("notify: mount(2) argument exceeds PATH_MAX with no terminating NUL")
+}
+
+func indexByte(b []byte, c byte) int {
+	for i, v := range b {
+		if v == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// mountInNamespace performs mount(source, target, fstype, flags, "") with
+// the calling OS thread's mount namespace switched to pid's for the
+// duration of the call, so the result is visible inside the container (the
+// notifying process's namespace) rather than the daemon's own.
+//
+// It always attempts to restore the thread's original mount namespace
+// before returning. If that restore fails, the thread is left locked
+// (never calling runtime.UnlockOSThread) so the Go runtime retires it
+// instead of handing a thread that's stuck in the wrong mount namespace
+// back to the pool for some unrelated goroutine to run on.
+func mountInNamespace(pid uint32, source, target, fstype string, flags uintptr) error {
+	targetNS, err := os.Open(fmt.Sprintf("/proc/%d/ns/mnt", pid))
+	if err != nil {
+		return err
+	}
+	defer targetNS.Close()
+
+	selfNS, err := os.Open("/proc/self/ns/mnt")
+	if err != nil {
+		return err
+	}
+	defer selfNS.Close()
+
+	runtime.LockOSThread()
+	if err := unix.Setns(int(targetNS.Fd()), unix.CLONE_NEWNS); err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	mountErr := unix.Mount(source, target, fstype, flags, "")
+	if err := unix.Setns(int(selfNS.Fd()), unix.CLONE_NEWNS); err != nil {
+		if mountErr == nil {
+			mountErr = err
+		}
+		return mountErr
+	}
+	runtime.UnlockOSThread()
+	return mountErr
+}
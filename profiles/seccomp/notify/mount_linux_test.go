@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+)
+
+func TestReadNotifyCString(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "notify-mem")
+	assert.NilError(t, err)
+	defer f.Close()
+
+	// Address 0 is rejected as a null pointer, so the strings start at 1.
+	_, err = f.Write([]byte("-hello\x00world\x00"))
+	assert.NilError(t, err)
+
+	got, err := readNotifyCString(f, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, got, "hello")
+
+	got, err = readNotifyCString(f, 7)
+	assert.NilError(t, err)
+	assert.Equal(t, got, "world")
+}
+
+func TestReadNotifyCStringRejectsNullAddress(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "notify-mem")
+	assert.NilError(t, err)
+	defer f.Close()
+
+	_, err = readNotifyCString(f, 0)
+	assert.ErrorContains(t, err, "null mount(2) argument")
+}
+
+func TestReadNotifyCStringRejectsUnterminatedString(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "notify-mem")
+	assert.NilError(t, err)
+	defer f.Close()
+
+	noNUL := make([]byte, maxNotifyCStringLen+1)
+	for i := range noNUL {
+		noNUL[i] = 'a'
+	}
+	_, err = f.Write(noNUL)
+	assert.NilError(t, err)
+
+	// Read from 1: address 0 is rejected as a null pointer before any read.
+	_, err = readNotifyCString(f, 1)
+	assert.ErrorContains(t, err, "exceeds PATH_MAX")
+}
+
+func TestAllowedMountFilesystems(t *testing.T) {
+	for _, fstype := range []string{"tmpfs", "proc", "overlay"} {
+		assert.Assert(t, allowedMountFilesystems[fstype], "%s should be allowed", fstype)
+	}
+	for _, fstype := range []string{"ext4", "nfs", "cifs", ""} {
+		assert.Assert(t, !allowedMountFilesystems[fstype], "%s should not be allowed", fstype)
+	}
+}
+
+func TestHandleDefaultMountDeniesWhenNotificationNotValid(t *testing.T) {
+	// NotifFd: 0 is never a real seccomp notify fd, so the SECCOMP_IOCTL_NOTIF_ID_VALID
+	// liveness check must fail and deny the mount before req.Pid is ever touched --
+	// acting on Pid without first confirming the notification is still live is
+	// exactly the TOCTOU this check exists to close.
+	resp, err := handleDefaultMount(nil, &SeccompNotif{Pid: 0, NotifFd: 0, ID: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Error, int32(unix.ENOENT))
+}
+
+func TestCheckNotifAliveRejectsInvalidFd(t *testing.T) {
+	// -1 is never a valid fd, so the ioctl must fail rather than report liveness.
+	err := checkNotifAlive(-1, 1)
+	assert.ErrorContains(t, err, "bad file descriptor")
+}
+
+func TestErrnoOf(t *testing.T) {
+	assert.Equal(t, errnoOf(unix.ENOENT), unix.ENOENT)
+	assert.Equal(t, errnoOf(assertErr{}), unix.EPERM)
+}
+
+type assertErr struct{}
+
+func (assertErr) Error() string { return "not an errno" }
@@ -0,0 +1,103 @@
+// Package notify implements the userspace side of SCMP_ACT_NOTIFY: handlers
+// that are asked to decide the outcome of a syscall the kernel would
+// otherwise have denied or allowed unconditionally, instead of the kernel
+// deciding on its own. Getting the notify fd out of the container's runtime
+// (runc's SECCOMP_FILTER_FLAG_NEW_LISTENER / --listenfd support) and into the
+// daemon is the OCI runtime integration's job; this package only concerns
+// itself with what happens once a request arrives on that fd.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SeccompNotif is the subset of the kernel's seccomp_notif struct (see
+// seccomp_unotify(2)) that handlers need to make a decision.
+//
+// NotifFd is the seccomp notify listener fd the kernel delivered this
+// request on. A handler that acts on Pid (entering its namespaces, reading
+// its /proc/<pid>/mem, ...) must re-validate ID against NotifFd via
+// SECCOMP_IOCTL_NOTIF_ID_VALID immediately before doing anything
+// irreversible: Pid can be recycled out from under a stale request between
+// the kernel capturing the syscall and the handler running, and without
+// that check the handler would act on whatever unrelated process now holds
+// the pid.
+type SeccompNotif struct {
+	ID      uint64
+	Pid     uint32
+	NotifFd int
+	Syscall string
+	Args    [6]uint64
+}
+
+// SeccompNotifResp is a handler's decision for a SeccompNotif: either Error
+// is returned to the caller as the syscall's result (errno), or the syscall
+// is allowed to continue with Flags set to permit it (SECCOMP_USER_NOTIF_FLAG_CONTINUE).
+type SeccompNotifResp struct {
+	ID    uint64
+	Error int32
+	Flags uint32
+	Val   uint64
+}
+
+// Handler decides the outcome of one notified syscall.
+type Handler interface {
+	Handle(ctx context.Context, req *SeccompNotif) (*SeccompNotifResp, error)
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ctx context.Context, req *SeccompNotif) (*SeccompNotifResp, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, req *SeccompNotif) (*SeccompNotifResp, error) {
+	return f(ctx, req)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Handler{}
+)
+
+// Register makes a Handler available by name, so it can be selected for a
+// container's HostConfig (e.g. via a "seccomp-notify-handler" option) by
+// third-party plugins as well as the handlers shipped in this package.
+func Register(name string, h Handler) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("seccomp notify handler already registered: %s", name)
+	}
+	registry[name] = h
+	return nil
+}
+
+// Lookup returns the Handler registered under name, if any.
+func Lookup(name string) (Handler, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	h, ok := registry[name]
+	return h, ok
+}
+
+// Candidates lists the syscalls this package is designed to intercept via
+// SCMP_ACT_NOTIFY in DefaultProfile, instead of a blanket deny. Forwarding
+// them to a handler lets a narrowly-scoped operation (e.g. mounting tmpfs in
+// a user-namespaced container) succeed without granting the syscall
+// unconditionally. DefaultProfile forwards Candidates with an ActNotify rule
+// on kernels where DetectLevel reports notify support; delivering those
+// notifications to a handler is left to the OCI runtime integration
+// mentioned in the package doc comment.
+var Candidates = []string{
+	"mount",
+	"umount2",
+	"chroot",
+	"bpf",
+	"perf_event_open",
+	"init_module",
+	"finit_module",
+}
+
+func init() {
+	Register("default-mount", HandlerFunc(handleDefaultMount))
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+)
+
+func TestRegisterAndLookup(t *testing.T) {
+	h := HandlerFunc(func(ctx context.Context, req *SeccompNotif) (*SeccompNotifResp, error) {
+		return &SeccompNotifResp{ID: req.ID}, nil
+	})
+
+	assert.NilError(t, Register("test-handler", h))
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "test-handler")
+		registryMu.Unlock()
+	}()
+
+	got, ok := Lookup("test-handler")
+	assert.Assert(t, ok)
+	resp, err := got.Handle(context.Background(), &SeccompNotif{ID: 42})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.ID, uint64(42))
+
+	err = Register("test-handler", h)
+	assert.ErrorContains(t, err, "seccomp notify handler already registered: test-handler")
+
+	_, ok = Lookup("does-not-exist")
+	assert.Assert(t, !ok)
+}
+
+func TestDefaultMountHandlerRegistered(t *testing.T) {
+	h, ok := Lookup("default-mount")
+	assert.Assert(t, ok)
+	// NotifFd 0 isn't a seccomp notify fd, so the liveness check fails and
+	// the request is denied with ENOENT rather than erroring out of Handle
+	// itself -- see mount_linux_test.go for the whitelist/argument-decoding
+	// behavior.
+	resp, err := h.Handle(context.Background(), &SeccompNotif{})
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Error, int32(unix.ENOENT))
+}
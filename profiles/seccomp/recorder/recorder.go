@@ -0,0 +1,183 @@
+// Package recorder builds a minimal, tailored seccomp profile for a
+// container by watching which syscalls it actually makes, instead of
+// starting from profiles/seccomp.DefaultProfile's broad allow-list.
+//
+// Collecting (arch, syscall_nr) tuples as a container runs is the OCI
+// runtime integration's job (ptrace, an SCMP_ACT_LOG profile, or an eBPF
+// raw_syscalls/sys_enter tracepoint, depending on what the kernel and
+// runtime support); this package owns the recording buffer and turning what
+// was collected into a profile.
+package recorder
+
+import (
+	"sync"
+
+	"github.com/moby/moby/v2/profiles/seccomp"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+// NameLookup translates a (arch, syscall number) pair observed by the
+// tracer into the syscall's name, e.g. via libseccomp's arch tables. It's
+// supplied by the caller because the lookup tables are arch- and
+// libseccomp-version-specific.
+type NameLookup func(arch specs.Arch, nr int) (name string, ok bool)
+
+// Recording is a dedup'd, capped buffer of syscalls observed for one
+// container. Call Observe for every syscall the tracer reports, then Profile
+// to turn it into an OCI seccomp profile.
+type Recording struct {
+	maxSyscalls int
+	lookup      NameLookup
+
+	mu      sync.Mutex
+	byArch  map[specs.Arch]map[int]struct{}
+	stopped bool
+	onDeny  func()
+}
+
+// New creates a Recording that caps itself at maxSyscalls distinct (arch,
+// nr) tuples; once the cap is hit, further Observe calls are ignored rather
+// than growing the buffer without bound. maxSyscalls <= 0 means unbounded.
+func New(lookup NameLookup, maxSyscalls int) *Recording {
+	return &Recording{
+		maxSyscalls: maxSyscalls,
+		lookup:      lookup,
+		byArch:      map[specs.Arch]map[int]struct{}{},
+	}
+}
+
+// StopOnFirstDeny arranges for fn to be called the first time Observe sees a
+// syscall that Recording can't resolve to a name (and so can't allow-list),
+// and for the recording to stop there (later Observe calls are ignored), so
+// a CI job recording a known-good workload can fail fast instead of
+// shipping an incomplete profile.
+func (r *Recording) StopOnFirstDeny(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onDeny = fn
+}
+
+// Observe records one occurrence of a syscall.
+func (r *Recording) Observe(arch specs.Arch, nr int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	if _, ok := r.lookup(arch, nr); !ok {
+		if r.onDeny != nil {
+			r.onDeny()
+			r.stopped = true
+		}
+		return
+	}
+	set, ok := r.byArch[arch]
+	if !ok {
+		set = map[int]struct{}{}
+		r.byArch[arch] = set
+	}
+	if _, seen := set[nr]; seen {
+		return
+	}
+	if r.maxSyscalls > 0 && r.total() >= r.maxSyscalls {
+		r.stopped = true
+		return
+	}
+	set[nr] = struct{}{}
+}
+
+func (r *Recording) total() int {
+	n := 0
+	for _, set := range r.byArch {
+		n += len(set)
+	}
+	return n
+}
+
+// Profile renders the recording as an OCI seccomp profile: DefaultAction
+// denies with ENOSYS (profiles/seccomp.DefaultProfile denies with EPERM),
+// and one allow rule per architecture observed, listing every syscall name recorded
+// for it merged with baseline (e.g. a previous recording, or a hand-written
+// minimum). Names that baseline and the recording both carry for an
+// architecture are only listed once.
+func (r *Recording) Profile(baseline *seccomp.Seccomp) *seccomp.Seccomp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := map[specs.Arch]map[string]struct{}{}
+	for arch, set := range r.byArch {
+		names[arch] = map[string]struct{}{}
+		for nr := range set {
+			if name, ok := r.lookup(arch, nr); ok {
+				names[arch][name] = struct{}{}
+			}
+		}
+	}
+	if baseline != nil {
+		for _, sc := range baseline.Syscalls {
+			if sc.Action != specs.ActAllow {
+				continue
+			}
+			arches := archesFor(baseline, sc)
+			for _, arch := range arches {
+				if names[arch] == nil {
+					names[arch] = map[string]struct{}{}
+				}
+				for _, n := range sc.Names {
+					names[arch][n] = struct{}{}
+				}
+			}
+		}
+	}
+
+	nosys := uint(unix.ENOSYS)
+	p := &seccomp.Seccomp{
+		LinuxSeccomp: specs.LinuxSeccomp{
+			DefaultAction:   specs.ActErrno,
+			DefaultErrnoRet: &nosys,
+		},
+	}
+	for arch, set := range names {
+		var list []string
+		for n := range set {
+			list = append(list, n)
+		}
+		p.ArchMap = append(p.ArchMap, seccomp.Architecture{Arch: arch})
+		sc := &seccomp.Syscall{
+			LinuxSyscall: specs.LinuxSyscall{
+				Names:  list,
+				Action: specs.ActAllow,
+			},
+		}
+		if name := seccomp.ArchName(arch); name != "" {
+			sc.Includes = &seccomp.Filter{Arches: []string{name}}
+		}
+		p.Syscalls = append(p.Syscalls, sc)
+	}
+	return p
+}
+
+// archesFor returns the architectures a baseline allow-rule applies to: all
+// of them, if the rule carries no Includes.Arches filter, or the ones the
+// filter names translated back into specs.Arch values we recognise.
+func archesFor(baseline *seccomp.Seccomp, sc *seccomp.Syscall) []specs.Arch {
+	if sc.Includes == nil || len(sc.Includes.Arches) == 0 {
+		arches := make([]specs.Arch, 0, len(baseline.ArchMap))
+		for _, a := range baseline.ArchMap {
+			arches = append(arches, a.Arch)
+		}
+		return arches
+	}
+	var arches []specs.Arch
+	for _, a := range baseline.ArchMap {
+		name := seccomp.ArchName(a.Arch)
+		for _, want := range sc.Includes.Arches {
+			if name != "" && name == want {
+				arches = append(arches, a.Arch)
+				break
+			}
+		}
+	}
+	return arches
+}
@@ -0,0 +1,112 @@
+package recorder
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/moby/moby/v2/profiles/seccomp"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func namesFor(nr int) (string, bool) {
+	table := map[int]string{1: "read", 2: "write", 3: "open"}
+	name, ok := table[nr]
+	return name, ok
+}
+
+func TestObserveDedupAndCap(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 1)
+
+	r.Observe(specs.ArchX86_64, 1)
+	r.Observe(specs.ArchX86_64, 1) // duplicate, no-op
+	assert.Equal(t, r.total(), 1)
+
+	// Cap of 1 distinct syscall already hit: a new one is dropped.
+	r.Observe(specs.ArchX86_64, 2)
+	assert.Equal(t, r.total(), 1)
+}
+
+func TestObserveUnboundedWhenCapNonPositive(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 0)
+	r.Observe(specs.ArchX86_64, 1)
+	r.Observe(specs.ArchX86_64, 2)
+	r.Observe(specs.ArchX86_64, 3)
+	assert.Equal(t, r.total(), 3)
+}
+
+func TestStopOnFirstDeny(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 0)
+	denied := 0
+	r.StopOnFirstDeny(func() { denied++ })
+
+	r.Observe(specs.ArchX86_64, 999) // unresolvable: fn runs, recording stops
+	r.Observe(specs.ArchX86_64, 999) // ignored, recording already stopped
+	assert.Equal(t, denied, 1)
+	assert.Equal(t, r.total(), 0)
+}
+
+func TestProfileWithoutBaseline(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 0)
+	r.Observe(specs.ArchX86_64, 1)
+	r.Observe(specs.ArchX86_64, 2)
+
+	p := r.Profile(nil)
+	assert.Equal(t, p.DefaultAction, specs.ActErrno)
+	assert.Equal(t, len(p.Syscalls), 1)
+	assert.Equal(t, p.Syscalls[0].Action, specs.ActAllow)
+
+	names := append([]string(nil), p.Syscalls[0].Names...)
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"read", "write"})
+}
+
+func TestProfileMergesBaseline(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 0)
+	r.Observe(specs.ArchX86_64, 1) // "read"
+
+	baseline := &seccomp.Seccomp{
+		ArchMap: []seccomp.Architecture{{Arch: specs.ArchX86_64}},
+		Syscalls: []*seccomp.Syscall{
+			{
+				LinuxSyscall: specs.LinuxSyscall{Names: []string{"write"}, Action: specs.ActAllow},
+			},
+		},
+	}
+
+	p := r.Profile(baseline)
+	assert.Equal(t, len(p.Syscalls), 1)
+	names := append([]string(nil), p.Syscalls[0].Names...)
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"read", "write"})
+}
+
+// TestProfileMergesArchGatedBaselineRule covers a baseline allow-rule scoped
+// to a specific architecture via Includes.Arches, using the short,
+// GOARCH-style names DefaultProfile itself writes there (e.g. "amd64", not
+// the raw specs.Arch string "SCMP_ARCH_X86_64"). A rule gated on the arch
+// being recorded must be merged in; one gated on a different arch must not.
+func TestProfileMergesArchGatedBaselineRule(t *testing.T) {
+	r := New(func(arch specs.Arch, nr int) (string, bool) { return namesFor(nr) }, 0)
+	r.Observe(specs.ArchX86_64, 1) // "read"
+
+	baseline := &seccomp.Seccomp{
+		ArchMap: []seccomp.Architecture{{Arch: specs.ArchX86_64}},
+		Syscalls: []*seccomp.Syscall{
+			{
+				LinuxSyscall: specs.LinuxSyscall{Names: []string{"write"}, Action: specs.ActAllow},
+				Includes:     &seccomp.Filter{Arches: []string{"amd64"}},
+			},
+			{
+				LinuxSyscall: specs.LinuxSyscall{Names: []string{"s390_pci_mmio_read"}, Action: specs.ActAllow},
+				Includes:     &seccomp.Filter{Arches: []string{"s390", "s390x"}},
+			},
+		},
+	}
+
+	p := r.Profile(baseline)
+	assert.Equal(t, len(p.Syscalls), 1)
+	names := append([]string(nil), p.Syscalls[0].Names...)
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"read", "write"})
+}
@@ -109,9 +109,20 @@ func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImp
 		var cm solver.CacheManager
 		if prevCm, ok := b.cms[cmID]; !ok {
 			func(cmID string, im gw.CacheOptionsEntry) {
-				cm = newLazyCacheManager(cmID, func() (solver.CacheManager, error) {
+				// Resolving the importer needs a session.Group to satisfy
+				// auth callbacks and other session-scoped transports. The
+				// cache manager for a given cmID is shared across builds
+				// (it's cached in b.cms), so it can't just capture the
+				// session.Group of whichever build first requested it: that
+				// build may finish, and a later build in a different
+				// session would be resolving credentials that are no
+				// longer valid. Instead, defer resolution until the first
+				// real Query/Records/Load call, always using that call's
+				// own live session.Group; if it fails, the next call (quite
+				// possibly from a different session) gets to retry.
+				cm = newLazyCacheManager(cmID, func(ctx context.Context) (solver.CacheManager, error) {
 					var cmNew solver.CacheManager
-					if err := inBuilderContext(context.TODO(), b.builder, "importing cache manifest from "+cmID, "", func(ctx context.Context, g session.Group) error {
+					if err := inBuilderContext(ctx, b.builder, "importing cache manifest from "+cmID, "", func(ctx context.Context, g session.Group) error {
 						resolveCI, ok := b.resolveCacheImporterFuncs[im.Type]
 						if !ok {
 							return errors.Errorf("unknown cache importer: %s", im.Type)
@@ -383,72 +394,101 @@ func (b *llbBridge) ResolveSourceMetadata(ctx context.Context, op *pb.SourceOp,
 	return resp, nil
 }
 
+// lazyCacheManager defers resolving the underlying solver.CacheManager until
+// it's actually needed, and re-resolves it on every call as long as the
+// previous attempt failed. This keeps resolution tied to the session.Group
+// of whichever build is actually asking for it, rather than baking in the
+// session.Group of the first caller for the lifetime of the process; see the
+// comment where newLazyCacheManager is constructed in loadResult.
 type lazyCacheManager struct {
-	id   string
-	main solver.CacheManager
+	id      string
+	resolve func(ctx context.Context) (solver.CacheManager, error)
 
-	waitCh chan struct{}
-	err    error
+	mu       sync.Mutex
+	main     solver.CacheManager
+	err      error
+	resolved bool
+}
+
+func newLazyCacheManager(id string, resolve func(ctx context.Context) (solver.CacheManager, error)) solver.CacheManager {
+	return &lazyCacheManager{id: id, resolve: resolve}
 }
 
 func (lcm *lazyCacheManager) ID() string {
 	return lcm.id
 }
 
+// ensure resolves lcm.main using ctx's session.Group if it hasn't been
+// resolved yet, or if the last attempt failed.
+func (lcm *lazyCacheManager) ensure(ctx context.Context) error {
+	lcm.mu.Lock()
+	defer lcm.mu.Unlock()
+	if lcm.resolved && lcm.err == nil {
+		return nil
+	}
+	lcm.main, lcm.err = lcm.resolve(ctx)
+	lcm.resolved = true
+	return lcm.err
+}
+
+// Query has no context parameter in solver.CacheManager, so it can't carry a
+// session.Group of its own; it falls back to context.Background() for
+// resolution purposes only (auth-less importers, e.g. local/registry without
+// credentials, still resolve fine). Records/Load, which run right after in
+// the normal cache-key-matching path, re-resolve with their own ctx if this
+// attempt failed.
 func (lcm *lazyCacheManager) Query(inp []solver.CacheKeyWithSelector, inputIndex solver.Index, dgst digest.Digest, outputIndex solver.Index) ([]*solver.CacheKey, error) {
-	lcm.wait()
-	if lcm.main == nil {
+	if err := lcm.ensure(context.Background()); err != nil {
+		return nil, nil
+	}
+	lcm.mu.Lock()
+	main := lcm.main
+	lcm.mu.Unlock()
+	if main == nil {
 		return nil, nil
 	}
-	return lcm.main.Query(inp, inputIndex, dgst, outputIndex)
+	return main.Query(inp, inputIndex, dgst, outputIndex)
 }
 
 func (lcm *lazyCacheManager) Records(ctx context.Context, ck *solver.CacheKey) ([]*solver.CacheRecord, error) {
-	lcm.wait()
-	if lcm.main == nil {
+	if err := lcm.ensure(ctx); err != nil {
 		return nil, nil
 	}
 	return lcm.main.Records(ctx, ck)
 }
 
 func (lcm *lazyCacheManager) Load(ctx context.Context, rec *solver.CacheRecord) (solver.Result, error) {
-	if err := lcm.wait(); err != nil {
+	if err := lcm.ensure(ctx); err != nil {
 		return nil, err
 	}
 	return lcm.main.Load(ctx, rec)
 }
 
+// Save has the same no-context limitation as Query; see its comment.
 func (lcm *lazyCacheManager) Save(key *solver.CacheKey, s solver.Result, createdAt time.Time) (*solver.ExportableCacheKey, error) {
-	if err := lcm.wait(); err != nil {
+	if err := lcm.ensure(context.Background()); err != nil {
 		return nil, err
 	}
-	return lcm.main.Save(key, s, createdAt)
+	lcm.mu.Lock()
+	main := lcm.main
+	lcm.mu.Unlock()
+	if main == nil {
+		return nil, nil
+	}
+	return main.Save(key, s, createdAt)
 }
 
 func (lcm *lazyCacheManager) ReleaseUnreferenced(ctx context.Context) error {
-	if err := lcm.wait(); err != nil {
+	if err := lcm.ensure(ctx); err != nil {
 		return err
 	}
-	return lcm.main.ReleaseUnreferenced(ctx)
-}
-
-func (lcm *lazyCacheManager) wait() error {
-	<-lcm.waitCh
-	return lcm.err
-}
-
-func newLazyCacheManager(id string, fn func() (solver.CacheManager, error)) solver.CacheManager {
-	lcm := &lazyCacheManager{id: id, waitCh: make(chan struct{})}
-	go func() {
-		defer close(lcm.waitCh)
-		cm, err := fn()
-		if err != nil {
-			lcm.err = err
-			return
-		}
-		lcm.main = cm
-	}()
-	return lcm
+	lcm.mu.Lock()
+	main := lcm.main
+	lcm.mu.Unlock()
+	if main == nil {
+		return nil
+	}
+	return main.ReleaseUnreferenced(ctx)
 }
 
 func cmKey(im gw.CacheOptionsEntry) (string, error) {
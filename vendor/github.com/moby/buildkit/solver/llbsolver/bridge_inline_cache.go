@@ -0,0 +1,93 @@
+package llbsolver
+
+import (
+	"context"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/moby/buildkit/cache/remotecache"
+	remotecachelocal "github.com/moby/buildkit/cache/remotecache/local"
+	"github.com/moby/buildkit/session"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// inlineCacheAnnotation is the annotation, on an image's target (manifest)
+// descriptor, that holds the digest of the image's moby.buildkit.cache.v0
+// manifest. The image config itself is not read.
+const inlineCacheAnnotation = "buildkit/inlinecache"
+
+// cacheManifestMediaType is the media type of the blob inlineCacheAnnotation
+// points at -- a moby.buildkit.cache.v0 manifest, never the image manifest
+// itself.
+const cacheManifestMediaType = "application/vnd.buildkit.cacheconfig.v0"
+
+// LocalImageResolver looks up an already-pulled image by reference and, if
+// it carries an inline cache manifest (the moby.buildkit.cache.v0 blob whose
+// digest inlineCacheAnnotation holds), returns a descriptor for that manifest
+// pointing at local content. It returns found=false, rather than an error,
+// for any image that isn't present locally or doesn't carry inline cache
+// metadata, so the caller can fall through to the registry importer.
+type LocalImageResolver func(ctx context.Context, ref string) (desc ocispecs.Descriptor, found bool, err error)
+
+// NewLocalImageResolver returns a LocalImageResolver backed by the daemon's
+// own image store and content store. It never talks to a registry: a miss
+// here (unknown ref, or an image that predates inline cache) just means the
+// caller should fall back to the registry importer.
+func NewLocalImageResolver(imageStore images.Store, contentStore content.Store) LocalImageResolver {
+	return func(ctx context.Context, ref string) (ocispecs.Descriptor, bool, error) {
+		img, err := imageStore.Get(ctx, ref)
+		if err != nil {
+			return ocispecs.Descriptor{}, false, nil
+		}
+		dgst, ok := resolveInlineCacheDigest(img)
+		if !ok {
+			return ocispecs.Descriptor{}, false, nil
+		}
+		info, err := contentStore.Info(ctx, dgst)
+		if err != nil {
+			return ocispecs.Descriptor{}, false, nil
+		}
+		return ocispecs.Descriptor{
+			MediaType: cacheManifestMediaType,
+			Digest:    dgst,
+			Size:      info.Size,
+		}, true, nil
+	}
+}
+
+// resolveInlineCacheDigest returns the digest of the inline cache manifest
+// recorded on img, if any. This is the manifest inlineCacheAnnotation points
+// at -- a separate blob from img.Target itself -- so it's kept apart from
+// the content-store lookup above to make the mapping from annotation to
+// digest testable without a content store.
+func resolveInlineCacheDigest(img images.Image) (digest.Digest, bool) {
+	raw, ok := img.Target.Annotations[inlineCacheAnnotation]
+	if !ok || raw == "" {
+		return "", false
+	}
+	dgst, err := digest.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	return dgst, true
+}
+
+// newLocalFirstCacheImporter wraps a registry ResolveCacheImporterFunc so
+// that it first tries to satisfy a "--cache-from <image>" import from an
+// image the daemon already has locally, via localResolve, only falling back
+// to a registry round-trip on a miss. A local hit is served entirely from
+// contentStore: it never calls registry, so repeated builds reuse inline
+// cache from a `docker pull`ed image without hitting the registry, and work
+// offline after an initial pull.
+func newLocalFirstCacheImporter(registry remotecache.ResolveCacheImporterFunc, localResolve LocalImageResolver, contentStore content.Store) remotecache.ResolveCacheImporterFunc {
+	return func(ctx context.Context, g session.Group, attrs map[string]string) (remotecache.Importer, ocispecs.Descriptor, error) {
+		ref := attrs["ref"]
+		if ref != "" && localResolve != nil {
+			if desc, found, err := localResolve(ctx, ref); err == nil && found {
+				return remotecachelocal.NewImporter(contentStore), desc, nil
+			}
+		}
+		return registry(ctx, g, attrs)
+	}
+}
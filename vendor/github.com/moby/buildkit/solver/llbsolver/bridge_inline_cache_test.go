@@ -0,0 +1,51 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/containerd/containerd/images"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// TestResolveInlineCacheDigest checks that the digest returned is the one
+// inlineCacheAnnotation points at -- the inline cache manifest -- and not
+// the image's own manifest digest, even though both are present on img.
+func TestResolveInlineCacheDigest(t *testing.T) {
+	imageDigest := digest.NewDigestFromEncoded(digest.SHA256, "deadbeef")
+	cacheDigest := digest.NewDigestFromEncoded(digest.SHA256, "cafef00d")
+	img := images.Image{
+		Target: ocispecs.Descriptor{
+			Digest: imageDigest,
+			Annotations: map[string]string{
+				inlineCacheAnnotation: cacheDigest.String(),
+			},
+		},
+	}
+
+	dgst, ok := resolveInlineCacheDigest(img)
+	assert.Assert(t, ok)
+	assert.Equal(t, dgst, cacheDigest)
+	assert.Assert(t, dgst != img.Target.Digest, "inline cache digest must not be the image's own manifest digest")
+}
+
+func TestResolveInlineCacheDigestMissingAnnotation(t *testing.T) {
+	img := images.Image{
+		Target: ocispecs.Descriptor{Digest: digest.NewDigestFromEncoded(digest.SHA256, "deadbeef")},
+	}
+
+	_, ok := resolveInlineCacheDigest(img)
+	assert.Assert(t, !ok)
+}
+
+func TestResolveInlineCacheDigestInvalid(t *testing.T) {
+	img := images.Image{
+		Target: ocispecs.Descriptor{
+			Annotations: map[string]string{inlineCacheAnnotation: "not-a-digest"},
+		},
+	}
+
+	_, ok := resolveInlineCacheDigest(img)
+	assert.Assert(t, !ok)
+}
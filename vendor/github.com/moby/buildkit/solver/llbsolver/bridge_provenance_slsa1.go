@@ -0,0 +1,155 @@
+package llbsolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+)
+
+// slsa1PredicateType is the in-toto predicate type for SLSA Provenance v1.0,
+// as opposed to the v0.2 predicate that provenance.Capture is natively
+// shaped for.
+const slsa1PredicateType = "https://slsa.dev/provenance/v1"
+
+// inTotoStatement is the generic in-toto v1 statement envelope
+// (https://in-toto.io/Statement/v1), parameterized over the predicate.
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     slsa1Predicate  `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// slsa1Predicate is the SLSA Provenance v1.0 predicate
+// (https://slsa.dev/spec/v1.0/provenance).
+type slsa1Predicate struct {
+	BuildDefinition slsa1BuildDefinition `json:"buildDefinition"`
+	RunDetails      slsa1RunDetails      `json:"runDetails"`
+}
+
+type slsa1BuildDefinition struct {
+	BuildType            string          `json:"buildType"`
+	ResolvedDependencies []inTotoSubject `json:"resolvedDependencies,omitempty"`
+	ExternalParameters   map[string]any  `json:"externalParameters,omitempty"`
+}
+
+type slsa1RunDetails struct {
+	Builder slsa1Builder `json:"builder"`
+}
+
+type slsa1Builder struct {
+	ID string `json:"id"`
+}
+
+// buildTypeForFrontend returns the stable buildType URI for a frontend
+// name, as used by SolveRequest.Frontend ("dockerfile.v0", "gateway.v0",
+// ...): https://docker.com/buildkit/<frontend>/provenance/v1, with an empty
+// name treated as "llb.v0". Every name, known or not, gets a buildType
+// rather than an error, since new frontends shouldn't break provenance
+// export.
+func buildTypeForFrontend(frontend string) string {
+	if frontend == "" {
+		frontend = "llb.v0"
+	}
+	return fmt.Sprintf("https://docker.com/buildkit/%s/provenance/v1", frontend)
+}
+
+// resolvedDependencies maps a capture's recorded image sources into the
+// SLSA Provenance v1.0 buildDefinition.resolvedDependencies list: one
+// subject per base image pulled during the build, named by its reference
+// and keyed by the digest it resolved to.
+//
+// capture.Sources also carries git/http/local sources; those aren't mapped
+// yet, so a build whose only materials are e.g. a git context won't get a
+// resolvedDependencies entry for it. Images are the common case (base
+// images in a Dockerfile build) and the part of provenance consumers most
+// often want to verify, so they come first.
+func resolvedDependencies(capture *provenance.Capture) []inTotoSubject {
+	var deps []inTotoSubject
+	for _, img := range capture.Sources.Images {
+		deps = append(deps, inTotoSubject{
+			Name:   img.Ref,
+			Digest: map[string]string{img.Digest.Algorithm().String(): img.Digest.Encoded()},
+		})
+	}
+	return deps
+}
+
+// toSLSA1 converts a v0.2-shaped provenance.Capture into a SLSA Provenance
+// v1.0 predicate. The frontend name (recorded on the SolveRequest that
+// produced the capture) becomes the buildType; image sources become
+// resolvedDependencies (see resolvedDependencies), and the full v0.2
+// capture is still carried over as-is under externalParameters, since
+// that's the only place git/http/local materials and the rest of the v0.2
+// fields (args, secrets, network access, ...) are represented at all so
+// far.
+//
+// A SolveRequest knob to pick the predicate version, and DSSE/in-toto
+// signing of the resulting statement, both still need support this tree
+// has no SolveRequest or signing plumbing to hang off of; ProvenanceSLSA1
+// below is as far as this goes until that exists.
+func toSLSA1(ctx context.Context, builderID, frontendName string, capture *provenance.Capture) (*slsa1Predicate, error) {
+	if capture == nil {
+		return nil, nil
+	}
+
+	return &slsa1Predicate{
+		BuildDefinition: slsa1BuildDefinition{
+			BuildType:            buildTypeForFrontend(frontendName),
+			ResolvedDependencies: resolvedDependencies(capture),
+			ExternalParameters: map[string]any{
+				"frontend":   frontendName,
+				"provenance": capture,
+			},
+		},
+		RunDetails: slsa1RunDetails{
+			Builder: slsa1Builder{ID: builderID},
+		},
+	}, nil
+}
+
+// newInTotoStatement wraps pred in the generic in-toto v1 statement
+// envelope (_type, subject, predicateType), so a SLSA1 predicate is never
+// handed to a caller except already embedded in the envelope format
+// consumers (in-toto verifiers, cosign attest --type slsaprovenance1)
+// actually expect on the wire.
+func newInTotoStatement(pred slsa1Predicate, subject []inTotoSubject) *inTotoStatement {
+	return &inTotoStatement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       subject,
+		PredicateType: slsa1PredicateType,
+		Predicate:     pred,
+	}
+}
+
+// ProvenanceSLSA1 returns the SLSA Provenance v1.0 in-toto statement for
+// this result, alongside the existing v0.2 Provenance() output. builderID
+// identifies the daemon producing the build (e.g. its engine ID), and is
+// used as runDetails.builder.id.
+//
+// subject is the statement's subject -- the name+digest pair(s) the
+// attestation is about. That's the exported image, and only the image
+// exporter knows its final ref and manifest digest (push can still change
+// the digest via repo compression/manifest rewriting), so subject has to
+// come from there rather than be computed here; this result alone can't
+// produce it. Calling ProvenanceSLSA1 with that subject once it has one is
+// still a caller this tree doesn't have yet -- there's no image exporter
+// in this snapshot -- but the statement it would send is now the real
+// inTotoStatement envelope, not a bare predicate a caller would have had to
+// wrap itself.
+func (rp *resultProxy) ProvenanceSLSA1(ctx context.Context, builderID string, subject []inTotoSubject) (*inTotoStatement, error) {
+	if rp.provenance == nil {
+		return nil, nil
+	}
+	pred, err := toSLSA1(ctx, builderID, rp.req.Frontend, rp.provenance)
+	if err != nil {
+		return nil, err
+	}
+	return newInTotoStatement(*pred, subject), nil
+}
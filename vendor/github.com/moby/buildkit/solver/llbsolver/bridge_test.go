@@ -0,0 +1,166 @@
+package llbsolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/solver"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+// fakeCacheManager is a minimal solver.CacheManager double for exercising
+// lazyCacheManager's delegation without a real importer behind it.
+type fakeCacheManager struct {
+	id                      string
+	releaseUnreferencedErr  error
+	releaseUnreferencedCall int
+}
+
+func (f *fakeCacheManager) ID() string { return f.id }
+
+func (f *fakeCacheManager) Query(inp []solver.CacheKeyWithSelector, inputIndex solver.Index, dgst digest.Digest, outputIndex solver.Index) ([]*solver.CacheKey, error) {
+	return nil, nil
+}
+
+func (f *fakeCacheManager) Records(ctx context.Context, ck *solver.CacheKey) ([]*solver.CacheRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeCacheManager) Load(ctx context.Context, rec *solver.CacheRecord) (solver.Result, error) {
+	return nil, nil
+}
+
+func (f *fakeCacheManager) Save(key *solver.CacheKey, s solver.Result, createdAt time.Time) (*solver.ExportableCacheKey, error) {
+	return &solver.ExportableCacheKey{}, nil
+}
+
+func (f *fakeCacheManager) ReleaseUnreferenced(ctx context.Context) error {
+	f.releaseUnreferencedCall++
+	return f.releaseUnreferencedErr
+}
+
+var errResolve = errors.New("resolve failed")
+
+// resolveSequence returns a resolve func that fails the first n calls and
+// succeeds with cm on every call after that, so tests can exercise
+// ensure's "re-resolve after a failed attempt" path.
+func resolveSequence(cm solver.CacheManager, failCount int) func(ctx context.Context) (solver.CacheManager, error) {
+	calls := 0
+	return func(ctx context.Context) (solver.CacheManager, error) {
+		calls++
+		if calls <= failCount {
+			return nil, errResolve
+		}
+		return cm, nil
+	}
+}
+
+func TestLazyCacheManagerIDDoesNotResolve(t *testing.T) {
+	lcm := newLazyCacheManager("my-id", func(ctx context.Context) (solver.CacheManager, error) {
+		t.Fatal("resolve should not be called by ID")
+		return nil, nil
+	})
+	assert.Equal(t, lcm.ID(), "my-id")
+}
+
+func TestLazyCacheManagerQuery(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	lcm := newLazyCacheManager("id", resolveSequence(cm, 0))
+	recs, err := lcm.Query(nil, 0, digest.Digest(""), 0)
+	assert.NilError(t, err)
+	assert.Assert(t, recs == nil)
+}
+
+func TestLazyCacheManagerQueryResolveFailure(t *testing.T) {
+	lcm := newLazyCacheManager("id", resolveSequence(nil, 1))
+	_, err := lcm.Query(nil, 0, digest.Digest(""), 0)
+	assert.NilError(t, err, "Query has no context to surface a resolve error with, so it degrades to an empty result")
+}
+
+func TestLazyCacheManagerSave(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	lcm := newLazyCacheManager("id", resolveSequence(cm, 0))
+	key, err := lcm.Save(nil, nil, time.Time{})
+	assert.NilError(t, err)
+	assert.Assert(t, key != nil)
+}
+
+func TestLazyCacheManagerSaveResolveFailure(t *testing.T) {
+	lcm := newLazyCacheManager("id", resolveSequence(nil, 1))
+	_, err := lcm.Save(nil, nil, time.Time{})
+	assert.Error(t, err, errResolve.Error())
+}
+
+func TestLazyCacheManagerSaveReResolvesAfterFailure(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	resolve := resolveSequence(cm, 1)
+	lcm := newLazyCacheManager("id", resolve)
+
+	_, err := lcm.Save(nil, nil, time.Time{})
+	assert.Error(t, err, errResolve.Error())
+
+	key, err := lcm.Save(nil, nil, time.Time{})
+	assert.NilError(t, err)
+	assert.Assert(t, key != nil)
+}
+
+func TestLazyCacheManagerRecordsResolveFailure(t *testing.T) {
+	lcm := newLazyCacheManager("id", resolveSequence(nil, 1))
+	recs, err := lcm.Records(context.Background(), nil)
+	assert.NilError(t, err)
+	assert.Assert(t, recs == nil)
+}
+
+func TestLazyCacheManagerLoad(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	lcm := newLazyCacheManager("id", resolveSequence(cm, 0))
+	_, err := lcm.Load(context.Background(), nil)
+	assert.NilError(t, err)
+}
+
+func TestLazyCacheManagerLoadResolveFailure(t *testing.T) {
+	lcm := newLazyCacheManager("id", resolveSequence(nil, 1))
+	_, err := lcm.Load(context.Background(), nil)
+	assert.Error(t, err, errResolve.Error())
+}
+
+func TestLazyCacheManagerReleaseUnreferenced(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	lcm := newLazyCacheManager("id", resolveSequence(cm, 0))
+	assert.NilError(t, lcm.ReleaseUnreferenced(context.Background()))
+	assert.Equal(t, cm.releaseUnreferencedCall, 1)
+}
+
+// TestLazyCacheManagerReleaseUnreferencedResolveFailure checks that a
+// failed resolve is propagated to the caller, not swallowed -- swallowing
+// it would let a cache-release call silently "succeed" despite the
+// importer never having been resolved.
+func TestLazyCacheManagerReleaseUnreferencedResolveFailure(t *testing.T) {
+	lcm := newLazyCacheManager("id", resolveSequence(nil, 1))
+	err := lcm.ReleaseUnreferenced(context.Background())
+	assert.Error(t, err, errResolve.Error())
+}
+
+func TestLazyCacheManagerReleaseUnreferencedPropagatesUnderlyingError(t *testing.T) {
+	wantErr := errors.New("release failed")
+	cm := &fakeCacheManager{id: "cm", releaseUnreferencedErr: wantErr}
+	lcm := newLazyCacheManager("id", resolveSequence(cm, 0))
+	err := lcm.ReleaseUnreferenced(context.Background())
+	assert.Error(t, err, wantErr.Error())
+}
+
+func TestLazyCacheManagerReleaseUnreferencedReResolvesAfterFailure(t *testing.T) {
+	cm := &fakeCacheManager{id: "cm"}
+	resolve := resolveSequence(cm, 1)
+	lcm := newLazyCacheManager("id", resolve)
+
+	err := lcm.ReleaseUnreferenced(context.Background())
+	assert.Error(t, err, errResolve.Error())
+
+	err = lcm.ReleaseUnreferenced(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, cm.releaseUnreferencedCall, 1)
+}